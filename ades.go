@@ -0,0 +1,447 @@
+package gompcreader
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+The ADES fields this package understands, in the order they are written.
+
+See https://minorplanetcenter.net/iau/info/ADES.html for the full standard.
+*/
+var adesFieldNames = []string{
+	"permID", "provID", "trkSub", "mode", "stn", "sys", "ctr", "pos1", "pos2",
+	"pos3", "obsTime", "ra", "dec", "rmsRA", "rmsDec", "astCat", "mag", "band",
+	"notes", "disc",
+}
+
+const adesVersion = "2017"
+
+/*
+Converts a set of ADES field values into an Observation. Errors are returned
+as a *ParseError with the ADES field name.
+*/
+func observationFromADES(fields map[string]string) (*Observation, error) {
+	var r Observation
+	var err error
+
+	if v := fields["permID"]; v != "" {
+		d, err := ParseDesignation(v)
+		if err != nil {
+			return nil, adesError("permID", err)
+		}
+		if !d.IsNumbered() {
+			return nil, adesError("permID", designationError(v, "permID is not a minor planet number"))
+		}
+		r.Number = d.Number
+	}
+	if v := fields["provID"]; v != "" {
+		d, err := ParseDesignation(v)
+		if err != nil {
+			return nil, adesError("provID", err)
+		}
+		r.ProvisionalDesignation = d.String()
+	}
+	r.TemporaryDesignation = fields["trkSub"]
+	if err := r.setID(); err != nil {
+		return nil, adesError("permID", err)
+	}
+
+	r.Mode = fields["mode"]
+	r.ObservatoryCode = fields["stn"]
+
+	switch fields["sys"] {
+	case "":
+	case "ICRF_KM", "ICRF_AU":
+		var s SatellitePosition
+		s.Units = 1
+		if fields["sys"] == "ICRF_AU" {
+			s.Units = 2
+		}
+		if s.X, s.Y, s.Z, err = adesPosition(fields); err != nil {
+			return nil, err
+		}
+		r.Satellite = &s
+	case "WGS84":
+		var v RovingPosition
+		if v.Longitude, v.Latitude, v.Altitude, err = adesPosition(fields); err != nil {
+			return nil, err
+		}
+		r.Roving = &v
+	default:
+		return nil, adesError("sys", fmt.Errorf("unsupported coordinate system %q", fields["sys"]))
+	}
+	r.Note2 = modeNote2(&r)
+
+	r.Time, err = time.Parse(time.RFC3339Nano, fields["obsTime"])
+	if err != nil {
+		return nil, adesError("obsTime", err)
+	}
+	r.Time = r.Time.UTC()
+
+	if r.RightAscension, err = strconv.ParseFloat(fields["ra"], 64); err != nil {
+		return nil, adesError("ra", err)
+	}
+	if r.Declination, err = strconv.ParseFloat(fields["dec"], 64); err != nil {
+		return nil, adesError("dec", err)
+	}
+	if r.RMSRightAscension, err = adesOptionalFloat(fields, "rmsRA"); err != nil {
+		return nil, err
+	}
+	if r.RMSDeclination, err = adesOptionalFloat(fields, "rmsDec"); err != nil {
+		return nil, err
+	}
+	r.Catalog = fields["astCat"]
+	if r.Magnitude, err = adesOptionalFloat(fields, "mag"); err != nil {
+		return nil, err
+	}
+	if fields["mag"] == "" {
+		r.missing |= FieldMagnitude
+	}
+	r.Band = fields["band"]
+	r.Note1 = fields["notes"]
+	r.Discovery = fields["disc"] == "*"
+
+	return &r, nil
+}
+
+/*
+Converts an Observation into ADES field values. Fields that don't have a value
+are left out of the map.
+*/
+func observationToADES(o *Observation) map[string]string {
+	fields := make(map[string]string)
+	set := func(name string, value string) {
+		if value != "" {
+			fields[name] = value
+		}
+	}
+	setFloat := func(name string, value float64) {
+		if value != 0 {
+			fields[name] = strconv.FormatFloat(value, 'f', -1, 64)
+		}
+	}
+
+	if o.Number > 0 {
+		set("permID", strconv.FormatInt(o.Number, 10))
+	}
+	set("provID", o.ProvisionalDesignation)
+	set("trkSub", o.TemporaryDesignation)
+	mode := o.Mode
+	if mode == "" {
+		mode = note2Mode(o.Note2)
+	}
+	set("mode", mode)
+	set("stn", o.ObservatoryCode)
+
+	switch {
+	case o.Satellite != nil:
+		set("sys", "ICRF_KM")
+		if o.Satellite.Units == 2 {
+			set("sys", "ICRF_AU")
+		}
+		set("ctr", "399")
+		fields["pos1"] = strconv.FormatFloat(o.Satellite.X, 'f', -1, 64)
+		fields["pos2"] = strconv.FormatFloat(o.Satellite.Y, 'f', -1, 64)
+		fields["pos3"] = strconv.FormatFloat(o.Satellite.Z, 'f', -1, 64)
+	case o.Roving != nil:
+		set("sys", "WGS84")
+		fields["pos1"] = strconv.FormatFloat(o.Roving.Longitude, 'f', -1, 64)
+		fields["pos2"] = strconv.FormatFloat(o.Roving.Latitude, 'f', -1, 64)
+		fields["pos3"] = strconv.FormatFloat(o.Roving.Altitude, 'f', -1, 64)
+	}
+
+	set("obsTime", o.Time.UTC().Format("2006-01-02T15:04:05.000Z"))
+	fields["ra"] = strconv.FormatFloat(o.RightAscension, 'f', -1, 64)
+	fields["dec"] = strconv.FormatFloat(o.Declination, 'f', -1, 64)
+	setFloat("rmsRA", o.RMSRightAscension)
+	setFloat("rmsDec", o.RMSDeclination)
+	set("astCat", o.Catalog)
+	if o.HasMagnitude() {
+		fields["mag"] = strconv.FormatFloat(o.Magnitude, 'f', -1, 64)
+	}
+	set("band", o.Band)
+	set("notes", o.Note1)
+	if o.Discovery {
+		set("disc", "*")
+	}
+	return fields
+}
+
+func adesError(field string, err error) error {
+	return &ParseError{Field: field, Err: err}
+}
+
+func adesOptionalFloat(fields map[string]string, name string) (float64, error) {
+	v := fields[name]
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, adesError(name, err)
+	}
+	return f, nil
+}
+
+func adesPosition(fields map[string]string) (float64, float64, float64, error) {
+	var result [3]float64
+	for i, name := range []string{"pos1", "pos2", "pos3"} {
+		v, err := strconv.ParseFloat(fields[name], 64)
+		if err != nil {
+			return 0, 0, 0, adesError(name, err)
+		}
+		result[i] = v
+	}
+	return result[0], result[1], result[2], nil
+}
+
+/*
+ADESReader reads observations from an ADES pipe separated value (PSV) file. It
+works in the same way as the other readers in this package, construct it with
+NewADESReader(string) and call ReadEntry() until it returns io.EOF, then
+Close() it.
+
+Header lines starting with "#" or "!" are skipped. The first line after them
+gives the field names for the rows that follow.
+*/
+type ADESReader struct {
+	src   *lineSource
+	names []string
+}
+
+/*
+NewADESReader opens the ADES PSV file at filePath. Compression is detected in
+the same way as NewMpcReader.
+*/
+func NewADESReader(filePath string) (*ADESReader, error) {
+	src, err := openLineSource(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return &ADESReader{src: src}, nil
+}
+
+/*
+NewADESReaderFromReader creates an ADES PSV reader that reads from in. Closing
+the returned reader will not close in.
+*/
+func NewADESReaderFromReader(in io.Reader) (*ADESReader, error) {
+	src, err := newLineSource(in, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ADESReader{src: src}, nil
+}
+
+/*
+NewADESReaderFromReadCloser creates an ADES PSV reader that takes ownership of
+in and closes it when the reader is closed.
+*/
+func NewADESReaderFromReadCloser(in io.ReadCloser) (*ADESReader, error) {
+	src, err := newLineSource(in, in)
+	if err != nil {
+		return nil, err
+	}
+	return &ADESReader{src: src}, nil
+}
+
+/*
+ReadEntry returns the next observation from the file.
+
+If the record could not be converted the error will be a *ParseError with the
+ADES field name. This will return io.EOF when the end of the file is reached.
+*/
+func (reader *ADESReader) ReadEntry() (*Observation, error) {
+	for {
+		line, err := reader.src.next()
+		if err != nil {
+			return nil, err
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if trimmed[0] == '#' || trimmed[0] == '!' {
+			// a new header starts a new block with its own field names
+			reader.names = nil
+			continue
+		}
+
+		values := splitPSV(line)
+		if reader.names == nil {
+			reader.names = values
+			continue
+		}
+		if len(values) != len(reader.names) {
+			return nil, reader.src.locate(&ParseError{
+				Field:  "row",
+				Record: line,
+				Err:    fmt.Errorf("expected %d fields but found %d", len(reader.names), len(values)),
+			})
+		}
+
+		fields := make(map[string]string, len(values))
+		for i, name := range reader.names {
+			fields[name] = values[i]
+		}
+		result, err := observationFromADES(fields)
+		if err != nil {
+			if pe, ok := err.(*ParseError); ok {
+				pe.Record = line
+			}
+			return nil, reader.src.locate(err)
+		}
+		return result, nil
+	}
+}
+
+/*
+Close the reader down. This will clean up the open file handle.
+*/
+func (reader *ADESReader) Close() {
+	reader.src.close()
+}
+
+func splitPSV(line string) []string {
+	values := strings.Split(line, "|")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+/*
+ADESWriter writes observations as an ADES PSV file. Should be constructed
+using NewADESWriter(io.Writer). Output is buffered so Flush() must be called
+once all the observations have been written.
+*/
+type ADESWriter struct {
+	w             *bufio.Writer
+	headerWritten bool
+}
+
+/*
+NewADESWriter creates a writer that writes observations to out. The caller is
+responsible for closing out once the writer has been flushed.
+*/
+func NewADESWriter(out io.Writer) *ADESWriter {
+	return &ADESWriter{w: bufio.NewWriter(out)}
+}
+
+/*
+WriteEntry writes a single observation. The version header and the field name
+row are written before the first observation.
+*/
+func (writer *ADESWriter) WriteEntry(o *Observation) error {
+	if !writer.headerWritten {
+		if _, err := fmt.Fprintf(writer.w, "# version=%s\n%s\n", adesVersion, strings.Join(adesFieldNames, "|")); err != nil {
+			return err
+		}
+		writer.headerWritten = true
+	}
+
+	fields := observationToADES(o)
+	values := make([]string, len(adesFieldNames))
+	for i, name := range adesFieldNames {
+		values[i] = fields[name]
+	}
+	_, err := fmt.Fprintln(writer.w, strings.Join(values, "|"))
+	return err
+}
+
+/*
+Flush writes any buffered observations to the underlying io.Writer.
+*/
+func (writer *ADESWriter) Flush() error {
+	return writer.w.Flush()
+}
+
+type adesXMLDocument struct {
+	XMLName xml.Name       `xml:"ades"`
+	Version string         `xml:"version,attr"`
+	Blocks  []adesXMLBlock `xml:"obsBlock"`
+}
+
+type adesXMLBlock struct {
+	Optical []adesXMLRecord `xml:"obsData>optical"`
+}
+
+type adesXMLRecord struct {
+	Fields []adesXMLField `xml:",any"`
+}
+
+type adesXMLField struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+/*
+ReadADESXML reads all the optical observations from an ADES XML document. The
+obsContext sections are not needed to build the observations so are ignored.
+
+Errors converting an observation are returned as a *ParseError, with Line set
+to the 1-based index of the observation in the document.
+*/
+func ReadADESXML(in io.Reader) ([]*Observation, error) {
+	var doc adesXMLDocument
+	if err := xml.NewDecoder(in).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var result []*Observation
+	for _, block := range doc.Blocks {
+		for _, record := range block.Optical {
+			fields := make(map[string]string, len(record.Fields))
+			for _, f := range record.Fields {
+				fields[f.XMLName.Local] = strings.TrimSpace(f.Value)
+			}
+			o, err := observationFromADES(fields)
+			if err != nil {
+				if pe, ok := err.(*ParseError); ok {
+					pe.Line = int64(len(result) + 1)
+				}
+				return nil, err
+			}
+			result = append(result, o)
+		}
+	}
+	return result, nil
+}
+
+/*
+WriteADESXML writes the observations as a single obsBlock of an ADES XML
+document. An obsContext describing the observatory and submitter is needed
+before the document can be submitted to the MPC, that is left to the caller.
+*/
+func WriteADESXML(out io.Writer, observations []*Observation) error {
+	var block adesXMLBlock
+	for _, o := range observations {
+		fields := observationToADES(o)
+		var record adesXMLRecord
+		for _, name := range adesFieldNames {
+			if v, ok := fields[name]; ok {
+				record.Fields = append(record.Fields, adesXMLField{XMLName: xml.Name{Local: name}, Value: v})
+			}
+		}
+		block.Optical = append(block.Optical, record)
+	}
+	doc := adesXMLDocument{Version: adesVersion, Blocks: []adesXMLBlock{block}}
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, "\n")
+	return err
+}
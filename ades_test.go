@@ -0,0 +1,187 @@
+package gompcreader
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readAllADES(t *testing.T, reader *ADESReader) []*Observation {
+	var result []*Observation
+	obs, err := reader.ReadEntry()
+	for err == nil {
+		result = append(result, obs)
+		obs, err = reader.ReadEntry()
+	}
+	assert.Equal(t, io.EOF, err)
+	return result
+}
+
+func TestReadADESXML(t *testing.T) {
+	f, err := os.Open("testdata/ades_sample.xml")
+	assert.Nil(t, err)
+	defer f.Close()
+
+	obs, err := ReadADESXML(f)
+	assert.Nil(t, err)
+	assert.Len(t, obs, 4)
+
+	eros := obs[0]
+	assert.Equal(t, "433", eros.ID)
+	assert.Equal(t, "CCD", eros.Mode)
+	assert.Equal(t, "C", eros.Note2)
+	assert.Equal(t, time.Date(2023, 1, 15, 2, 57, 46, 80000000, time.UTC), eros.Time)
+	assert.Equal(t, 78.1380125, eros.RightAscension)
+	assert.Equal(t, -23.1847556, eros.Declination)
+	assert.Equal(t, 0.12, eros.RMSRightAscension)
+	assert.Equal(t, 0.1, eros.RMSDeclination)
+	assert.Equal(t, "Gaia2", eros.Catalog)
+	assert.Equal(t, 12.3, eros.Magnitude)
+	assert.Equal(t, "V", eros.Band)
+	assert.Equal(t, "568", eros.ObservatoryCode)
+
+	provisional := obs[1]
+	assert.Equal(t, "2014 AB123", provisional.ID)
+	assert.False(t, provisional.Discovery)
+	assert.Equal(t, "K", provisional.Note1)
+
+	satellite := obs[2]
+	assert.Equal(t, "S", satellite.Note2)
+	assert.Equal(t, &SatellitePosition{Units: 1, X: -3548.621, Y: 5410.711, Z: 1430.342}, satellite.Satellite)
+
+	roving := obs[3]
+	assert.Equal(t, "100001", roving.ID)
+	assert.Equal(t, "V", roving.Note2)
+	assert.Equal(t, &RovingPosition{Longitude: 249.123456, Latitude: 32.123456, Altitude: 1234}, roving.Roving)
+}
+
+func TestADESXMLMatchesObservationFormat(t *testing.T) {
+	f, err := os.Open("testdata/ades_sample.xml")
+	assert.Nil(t, err)
+	defer f.Close()
+	ades, err := ReadADESXML(f)
+	assert.Nil(t, err)
+
+	reader, err := NewObservationReader(observationSamplePath)
+	assert.Nil(t, err)
+	defer reader.Close()
+	obs := readAllObservations(t, reader)
+
+	// The XML sample holds the same observations as lines 2 to 5 of the 80
+	// column sample, apart from the fields the old format can't carry.
+	for i, a := range ades {
+		expected := *obs[i+1]
+		expected.Mode = a.Mode
+		assertSameObservation(t, &expected, a)
+	}
+}
+
+func TestADESXMLRoundTrip(t *testing.T) {
+	reader, err := NewObservationReader(observationSamplePath)
+	assert.Nil(t, err)
+	defer reader.Close()
+	obs := readAllObservations(t, reader)
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteADESXML(&buf, obs))
+	assert.Contains(t, buf.String(), `<ades version="2017">`)
+
+	result, err := ReadADESXML(&buf)
+	assert.Nil(t, err)
+	assert.Len(t, result, len(obs))
+	for i := range obs {
+		// ADES only has the mode so the finer detail in Note2 is lost
+		expected := *obs[i]
+		expected.Note2 = modeNote2(&expected)
+		assertSameObservation(t, &expected, result[i])
+
+		// and back to the 80 column format
+		line, err := FormatObservation(result[i])
+		assert.Nil(t, err)
+		again, err := NewObservationReaderFromReader(strings.NewReader(line))
+		assert.Nil(t, err)
+		o, err := again.ReadEntry()
+		again.Close()
+		if assert.Nil(t, err, line) {
+			assertSameObservation(t, &expected, o)
+		}
+	}
+}
+
+func TestADESReader(t *testing.T) {
+	reader, err := NewADESReader("testdata/ades_sample.psv")
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	obs := readAllADES(t, reader)
+	assert.Len(t, obs, 3)
+	assert.Equal(t, "433", obs[0].ID)
+	assert.Equal(t, "Gaia2", obs[0].Catalog)
+	assert.Equal(t, "2014 AB123", obs[1].ID)
+	assert.True(t, obs[1].Discovery)
+	assert.Equal(t, "TEMP123", obs[2].ID)
+	assert.Equal(t, "500", obs[2].ObservatoryCode)
+	assert.Equal(t, 0.0, obs[2].Magnitude)
+}
+
+func TestADESWriterRoundTrip(t *testing.T) {
+	reader, err := NewObservationReader(observationSamplePath)
+	assert.Nil(t, err)
+	defer reader.Close()
+	obs := readAllObservations(t, reader)
+
+	var buf bytes.Buffer
+	writer := NewADESWriter(&buf)
+	for _, o := range obs {
+		assert.Nil(t, writer.WriteEntry(o))
+	}
+	assert.Nil(t, writer.Flush())
+	assert.True(t, strings.HasPrefix(buf.String(), "# version=2017\npermID|provID|"))
+
+	psv, err := NewADESReaderFromReader(&buf)
+	assert.Nil(t, err)
+	defer psv.Close()
+	result := readAllADES(t, psv)
+	assert.Len(t, result, len(obs))
+	for i := range obs {
+		expected := *obs[i]
+		expected.Note2 = modeNote2(&expected)
+		assertSameObservation(t, &expected, result[i])
+	}
+}
+
+func TestADESReaderErrors(t *testing.T) {
+	input := "permID|stn|obsTime|ra|dec\n" +
+		"433|568|2023-01-15T02:57:46.080Z|78.1380125|-23.1847556\n" +
+		"433|568|2023-01-15|78.1380125|-23.1847556\n" +
+		"433|568\n"
+	reader, err := NewADESReaderFromReader(strings.NewReader(input))
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	_, err = reader.ReadEntry()
+	assert.Nil(t, err)
+
+	_, err = reader.ReadEntry()
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, int64(3), pe.Line)
+	assert.Equal(t, "obsTime", pe.Field)
+	assert.True(t, strings.HasPrefix(err.Error(), "line 3: obsTime: "), err.Error())
+
+	_, err = reader.ReadEntry()
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, int64(4), pe.Line)
+	assert.Equal(t, "row", pe.Field)
+
+	_, err = ReadADESXML(strings.NewReader(`<ades version="2017"><obsBlock><obsData><optical><permID>2014 AB123</permID></optical></obsData></obsBlock></ades>`))
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "permID", pe.Field)
+	assert.True(t, errors.Is(err, ErrInvalidDesignation))
+}
@@ -0,0 +1,30 @@
+package gompcreader
+
+import (
+	"github.com/emilyselwood/gompcreader/photometry"
+)
+
+/*
+Photometry returns the H-G magnitude model for the minor planet. If the slope
+was blank photometry.DefaultSlope is used, the same as the MPC. The bool is
+false if there is no absolute magnitude, in which case the model is useless.
+*/
+func (p *MinorPlanet) Photometry() (photometry.HG, bool) {
+	g := p.Slope
+	if !p.HasSlope() {
+		g = photometry.DefaultSlope
+	}
+	return photometry.HG{H: p.AbsoluteMagnitude, G: g}, p.HasAbsoluteMagnitude()
+}
+
+/*
+Diameter estimates the size of the minor planet in km from its absolute
+magnitude and the given geometric albedo, see photometry.Diameter. The bool is
+false if the record had no absolute magnitude.
+*/
+func (p *MinorPlanet) Diameter(albedo float64) (float64, bool) {
+	if !p.HasAbsoluteMagnitude() {
+		return 0, false
+	}
+	return photometry.Diameter(p.AbsoluteMagnitude, albedo), true
+}
@@ -0,0 +1,74 @@
+package gompcreader
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/emilyselwood/gompcreader/photometry"
+)
+
+func blankColumns(line string, columns ...column) string {
+	for _, c := range columns {
+		line = line[:c.start] + strings.Repeat(" ", c.end-c.start) + line[c.end:]
+	}
+	return line
+}
+
+func TestBlankMagnitude(t *testing.T) {
+	ceres, err := ParseRecord(ceresLine)
+	assert.Nil(t, err)
+	assert.True(t, ceres.HasAbsoluteMagnitude())
+	assert.True(t, ceres.HasSlope())
+	model, ok := ceres.Photometry()
+	assert.True(t, ok)
+	assert.Equal(t, photometry.HG{H: 3.34, G: 0.12}, model)
+	size, ok := ceres.Diameter(0.09)
+	assert.True(t, ok)
+	assert.InDelta(t, 950, size, 5)
+
+	blank, err := ParseRecord(blankColumns(ceresLine, colAbsoluteMagnitude, colSlope))
+	assert.Nil(t, err)
+	assert.False(t, blank.HasAbsoluteMagnitude())
+	assert.False(t, blank.HasSlope())
+	_, ok = blank.Photometry()
+	assert.False(t, ok)
+	_, ok = blank.Diameter(photometry.DefaultAlbedo)
+	assert.False(t, ok)
+
+	rows, err := Ephemeris(blank, []time.Time{time.Date(2014, 4, 15, 0, 0, 0, 0, time.UTC)}, nil)
+	assert.Nil(t, err)
+	assert.True(t, math.IsNaN(rows[0].Magnitude))
+	assert.True(t, strings.HasSuffix(rows[0].String(), "      "))
+}
+
+func TestBlankSlopeUsesDefault(t *testing.T) {
+	p, err := ParseRecord(blankColumns(ceresLine, colSlope))
+	assert.Nil(t, err)
+	assert.True(t, p.HasAbsoluteMagnitude())
+	assert.False(t, p.HasSlope())
+	model, ok := p.Photometry()
+	assert.True(t, ok)
+	assert.Equal(t, photometry.HG{H: 3.34, G: photometry.DefaultSlope}, model)
+}
+
+func TestInvalidMagnitude(t *testing.T) {
+	line := ceresLine[:colAbsoluteMagnitude.start] + " 3x34" + ceresLine[colAbsoluteMagnitude.end:]
+	_, err := ParseRecord(line)
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "AbsoluteMagnitude", pe.Field)
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+}
+
+func TestMinorPlanetLiteralHasMagnitude(t *testing.T) {
+	// built in code rather than read from a file so everything is present
+	p := &MinorPlanet{AbsoluteMagnitude: 0}
+	assert.True(t, p.HasAbsoluteMagnitude())
+	assert.True(t, p.HasSlope())
+}
@@ -0,0 +1,326 @@
+package gompcreader
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+/*
+Comet is the result of reading a record from the MPC comet elements file,
+CometEls.txt, from https://www.minorplanetcenter.net/iau/MPCORB/CometEls.txt
+
+PerihelionTime is in TT, the same as the file. Epoch is the zero time if the
+orbit is unperturbed and no epoch was given. AbsoluteMagnitude and
+SlopeParameter can be blank in the file, use HasAbsoluteMagnitude and HasSlope
+to tell that apart from a real zero.
+*/
+type Comet struct {
+	PeriodicNumber              int64
+	OrbitType                   string
+	ProvisionalDesignation      string
+	PerihelionTime              time.Time
+	PerihelionDistance          float64
+	OrbitalEccentricity         float64
+	ArgumentOfPerihelion        float64
+	LongitudeOfTheAscendingNode float64
+	InclinationToTheEcliptic    float64
+	Epoch                       time.Time
+	AbsoluteMagnitude           float64
+	SlopeParameter              float64
+	DesignationAndName          string
+	Name                        string
+	Reference                   string
+
+	// missing records which of the optional columns were blank
+	missing OptionalFields
+}
+
+/*
+Missing returns the set of optional fields that were blank in the record. Only
+FieldAbsoluteMagnitude and FieldSlope apply to comets.
+*/
+func (c *Comet) Missing() OptionalFields {
+	return c.missing
+}
+
+/*
+HasAbsoluteMagnitude reports whether the AbsoluteMagnitude field has a value.
+*/
+func (c *Comet) HasAbsoluteMagnitude() bool {
+	return c.missing&FieldAbsoluteMagnitude == 0
+}
+
+/*
+HasSlope reports whether the SlopeParameter field has a value.
+*/
+func (c *Comet) HasSlope() bool {
+	return c.missing&FieldSlope == 0
+}
+
+/*
+CometReader reads records from the comet elements file. It works in the same
+way as MpcReader, construct it with NewCometReader(string) and call ReadEntry()
+until it returns io.EOF, then Close() it.
+*/
+type CometReader struct {
+	src *lineSource
+}
+
+/*
+NewCometReader opens the comet elements file at filePath. Compression is
+detected in the same way as NewMpcReader.
+*/
+func NewCometReader(filePath string) (*CometReader, error) {
+	src, err := openLineSource(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return &CometReader{src: src}, nil
+}
+
+/*
+NewCometReaderFromReader creates a comet reader that reads from in. Closing the
+returned reader will not close in.
+*/
+func NewCometReaderFromReader(in io.Reader) (*CometReader, error) {
+	src, err := newLineSource(in, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CometReader{src: src}, nil
+}
+
+/*
+NewCometReaderFromReadCloser creates a comet reader that takes ownership of in
+and closes it when the reader is closed.
+*/
+func NewCometReaderFromReadCloser(in io.ReadCloser) (*CometReader, error) {
+	src, err := newLineSource(in, in)
+	if err != nil {
+		return nil, err
+	}
+	return &CometReader{src: src}, nil
+}
+
+/*
+ReadEntry returns the next comet from the file. Blank lines are skipped.
+
+If the record could not be converted the error will be a *ParseError. This
+will return io.EOF when the end of the file is reached.
+*/
+func (reader *CometReader) ReadEntry() (*Comet, error) {
+	for {
+		line, err := reader.src.next()
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		result, err := ParseCometRecord(line)
+		if err != nil {
+			return nil, reader.src.locate(err)
+		}
+		return result, nil
+	}
+}
+
+/*
+Close the reader down. This will clean up the open file handle.
+*/
+func (reader *CometReader) Close() {
+	reader.src.close()
+}
+
+const cometRecordLength = 168
+
+var (
+	colCometPeriodicNumber              = column{"PeriodicNumber", 0, 4}
+	colCometOrbitType                   = column{"OrbitType", 4, 5}
+	colCometProvisionalDesignation      = column{"ProvisionalDesignation", 5, 12}
+	colCometPerihelionYear              = column{"PerihelionTime", 14, 18}
+	colCometPerihelionMonth             = column{"PerihelionTime", 19, 21}
+	colCometPerihelionDay               = column{"PerihelionTime", 22, 29}
+	colCometPerihelionDistance          = column{"PerihelionDistance", 30, 39}
+	colCometOrbitalEccentricity         = column{"OrbitalEccentricity", 41, 49}
+	colCometArgumentOfPerihelion        = column{"ArgumentOfPerihelion", 51, 59}
+	colCometLongitudeOfTheAscendingNode = column{"LongitudeOfTheAscendingNode", 61, 69}
+	colCometInclinationToTheEcliptic    = column{"InclinationToTheEcliptic", 71, 79}
+	colCometEpoch                       = column{"Epoch", 81, 89}
+	colCometAbsoluteMagnitude           = column{"AbsoluteMagnitude", 91, 95}
+	colCometSlopeParameter              = column{"SlopeParameter", 96, 100}
+	colCometDesignationAndName          = column{"DesignationAndName", 102, 158}
+	colCometReference                   = column{"Reference", 159, 168}
+)
+
+/*
+ParseCometRecord converts a single line of the comet elements file into a
+Comet. Trailing spaces are often trimmed from the file so short lines are
+padded out before the columns are read.
+*/
+func ParseCometRecord(line string) (*Comet, error) {
+	buffer := strings.TrimRight(line, "\r\n")
+	if len(buffer) < cometRecordLength {
+		buffer = buffer + strings.Repeat(" ", cometRecordLength-len(buffer))
+	}
+
+	var r Comet
+	var err error
+
+	number, err := colCometPeriodicNumber.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if number != "" {
+		r.PeriodicNumber, err = colCometPeriodicNumber.readInt(buffer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r.OrbitType, err = colCometOrbitType.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	r.ProvisionalDesignation, err = colCometProvisionalDesignation.readCometDesignation(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	r.PerihelionTime, err = readCometTime(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	r.PerihelionDistance, err = colCometPerihelionDistance.readFloat(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	r.OrbitalEccentricity, err = colCometOrbitalEccentricity.readFloat(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	r.ArgumentOfPerihelion, err = colCometArgumentOfPerihelion.readFloat(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	r.LongitudeOfTheAscendingNode, err = colCometLongitudeOfTheAscendingNode.readFloat(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	r.InclinationToTheEcliptic, err = colCometInclinationToTheEcliptic.readFloat(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	// The epoch is blank for unperturbed orbits
+	epoch, err := colCometEpoch.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if epoch != "" {
+		r.Epoch, err = colCometEpoch.readTime(buffer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var ok bool
+	r.AbsoluteMagnitude, ok, err = colCometAbsoluteMagnitude.readBlankableFloat(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		r.missing |= FieldAbsoluteMagnitude
+	}
+
+	r.SlopeParameter, ok, err = colCometSlopeParameter.readBlankableFloat(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		r.missing |= FieldSlope
+	}
+
+	r.DesignationAndName, err = colCometDesignationAndName.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+	r.Name = cometName(r.DesignationAndName)
+
+	r.Reference, err = colCometReference.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+/*
+The perihelion time is split over three columns with a fractional day
+*/
+func readCometTime(buffer string) (time.Time, error) {
+	year, err := colCometPerihelionYear.readInt(buffer)
+	if err != nil {
+		return time.Time{}, err
+	}
+	month, err := colCometPerihelionMonth.readInt(buffer)
+	if err != nil {
+		return time.Time{}, err
+	}
+	day, err := colCometPerihelionDay.readFloat(buffer)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if month < 1 || month > 12 || day < 0 || day >= 32 {
+		return time.Time{}, colCometPerihelionDay.error(buffer,
+			fmt.Errorf("invalid perihelion date %d-%d-%f", year, month, day))
+	}
+	return fractionalDate(int(year), time.Month(month), day), nil
+}
+
+/*
+Builds a time from a day of the month with a fractional part
+*/
+func fractionalDate(year int, month time.Month, day float64) time.Time {
+	whole := int(day)
+	fraction := time.Duration((day - float64(whole)) * float64(24*time.Hour))
+	return time.Date(year, month, whole, 0, 0, 0, 0, time.UTC).Add(fraction.Round(time.Millisecond))
+}
+
+func (c column) readCometDesignation(buffer string) (string, error) {
+	s, err := c.slice(buffer)
+	if err != nil {
+		return "", err
+	}
+	v, err := readPackedCometDesignation(readString(s))
+	if err != nil {
+		return "", c.error(buffer, err)
+	}
+	return v, nil
+}
+
+/*
+Pulls the name out of the designation and name column. Numbered comets are
+written as "1P/Halley" and others as "C/2020 F3 (NEOWISE)".
+*/
+func cometName(designation string) string {
+	if strings.HasSuffix(designation, ")") {
+		start := strings.Index(designation, "(")
+		if start >= 0 {
+			return designation[start+1 : len(designation)-1]
+		}
+	}
+	slash := strings.Index(designation, "/")
+	if slash > 0 && designation[0] >= '0' && designation[0] <= '9' {
+		return designation[slash+1:]
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package gompcreader
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const cometSamplePath = "testdata/CometEls_sample.txt"
+
+func TestCometReader(t *testing.T) {
+	reader, err := NewCometReader(cometSamplePath)
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	halley, err := reader.ReadEntry()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), halley.PeriodicNumber)
+	assert.Equal(t, "P", halley.OrbitType)
+	assert.Equal(t, "", halley.ProvisionalDesignation)
+	assert.Equal(t, time.Date(1986, 2, 9, 11, 0, 48, 960000000, time.UTC), halley.PerihelionTime)
+	assert.Equal(t, 0.5746, halley.PerihelionDistance)
+	assert.Equal(t, 0.967923, halley.OrbitalEccentricity)
+	assert.Equal(t, 112.2414, halley.ArgumentOfPerihelion)
+	assert.Equal(t, 59.8757, halley.LongitudeOfTheAscendingNode)
+	assert.Equal(t, 162.1912, halley.InclinationToTheEcliptic)
+	assert.Equal(t, time.Date(1986, 2, 19, 0, 0, 0, 0, time.UTC), halley.Epoch)
+	assert.Equal(t, 5.5, halley.AbsoluteMagnitude)
+	assert.Equal(t, 8.0, halley.SlopeParameter)
+	assert.Equal(t, "1P/Halley", halley.DesignationAndName)
+	assert.Equal(t, "Halley", halley.Name)
+	assert.Equal(t, "98, 39", halley.Reference)
+
+	neowise, err := reader.ReadEntry()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), neowise.PeriodicNumber)
+	assert.Equal(t, "C", neowise.OrbitType)
+	assert.Equal(t, "2020 F3", neowise.ProvisionalDesignation)
+	assert.Equal(t, "NEOWISE", neowise.Name)
+	assert.Equal(t, "MPC112396", neowise.Reference)
+
+	fragment, err := reader.ReadEntry()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(73), fragment.PeriodicNumber)
+	assert.Equal(t, "Schwassmann-Wachmann", fragment.Name)
+
+	unperturbed, err := reader.ReadEntry()
+	assert.Nil(t, err)
+	assert.Equal(t, "1996 J1-B", unperturbed.ProvisionalDesignation)
+	assert.True(t, unperturbed.Epoch.IsZero())
+	assert.Equal(t, "Evans-Drinkwater", unperturbed.Name)
+
+	_, err = reader.ReadEntry()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCometReaderTrimmedLines(t *testing.T) {
+	input := "\n    CK20F030  2020 07  3.6796  0.294609  0.999249   37.2784   61.0102  128.9375  20200719  12.5  3.2  C/2020 F3\n"
+	reader, err := NewCometReaderFromReader(strings.NewReader(input))
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	result, err := reader.ReadEntry()
+	assert.Nil(t, err)
+	assert.Equal(t, "C/2020 F3", result.DesignationAndName)
+	assert.Equal(t, "", result.Reference)
+}
+
+func TestCometReaderErrors(t *testing.T) {
+	input := "    CK20F030  2020 07  3.6796  0.29x609  0.999249   37.2784   61.0102  128.9375  20200719  12.5  3.2  C/2020 F3\n"
+	reader, err := NewCometReaderFromReader(strings.NewReader(input))
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	_, err = reader.ReadEntry()
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "PerihelionDistance", pe.Field)
+	assert.Equal(t, int64(1), pe.Line)
+
+	// H and G can be blank, but not garbage
+	_, err = ParseCometRecord("    CK20F030  2020 07  3.6796  0.294609  0.999249   37.2784   61.0102  128.9375  20200719  1x.5  3.2  C/2020 F3")
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "AbsoluteMagnitude", pe.Field)
+
+	result, err := ParseCometRecord("    CK20F030  2020 07  3.6796  0.294609  0.999249   37.2784   61.0102  128.9375  20200719            C/2020 F3")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, result.AbsoluteMagnitude)
+	assert.Equal(t, 0.0, result.SlopeParameter)
+	assert.False(t, result.HasAbsoluteMagnitude())
+	assert.False(t, result.HasSlope())
+	assert.Equal(t, FieldAbsoluteMagnitude|FieldSlope, result.Missing())
+
+	result, err = ParseCometRecord("    CK20F030  2020 07  3.6796  0.294609  0.999249   37.2784   61.0102  128.9375  20200719  12.5  0.0  C/2020 F3")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, result.SlopeParameter)
+	assert.True(t, result.HasAbsoluteMagnitude())
+	assert.True(t, result.HasSlope())
+	assert.Equal(t, OptionalFields(0), result.Missing())
+}
+
+var packedCometDesignationTests = []stringTestCase{
+	{"K20F030", "2020 F3"},
+	{"J96J01b", "1996 J1-B"},
+	{"K10A020", "2010 A2"},
+	{"K19A110", "2019 A11"},
+	{"J94P01A", "1994 PA1"},
+	{"", ""},
+}
+
+func TestReadPackedCometDesignation(t *testing.T) {
+	stringTest(t,
+		"readPackedCometDesignation",
+		packedCometDesignationTests,
+		func(c string) string {
+			r, e := readPackedCometDesignation(c)
+			assert.Nil(t, e)
+			return r
+		})
+
+	for _, tt := range []string{"K20F03", "K20I030", "K20F03!"} {
+		_, e := readPackedCometDesignation(tt)
+		assert.True(t, errors.Is(e, ErrInvalidDesignation), "readPackedCometDesignation(%q)", tt)
+	}
+}
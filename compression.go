@@ -0,0 +1,137 @@
+package gompcreader
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+/*
+Decompressor wraps a compressed stream and returns a reader of the
+decompressed data. Closing the returned io.ReadCloser should release any
+resources held by the decompressor but must not close the source stream.
+*/
+type Decompressor func(in io.Reader) (io.ReadCloser, error)
+
+type decompressor struct {
+	name  string
+	match func(header []byte) bool
+	open  Decompressor
+	size  int
+}
+
+var decompressorsLock sync.RWMutex
+var decompressors = []decompressor{
+	{name: "gzip", match: magicMatcher([]byte{0x1f, 0x8b}), open: openGzip, size: 2},
+	{name: "bzip2", match: magicMatcher([]byte("BZh")), open: openBzip2, size: 3},
+	{name: "zlib", match: isZlibHeader, open: zlib.NewReader, size: zlibTrialSize},
+}
+
+/*
+RegisterDecompressor adds support for another compression format. When the
+first bytes of a stream match magic the stream will be passed through open
+before being read.
+
+This allows formats that are not part of the standard library, such as xz or
+zstd, to be plugged in without this package depending on them. Registering a
+name that already exists replaces the previous entry.
+*/
+func RegisterDecompressor(name string, magic []byte, open Decompressor) {
+	m := make([]byte, len(magic))
+	copy(m, magic)
+	d := decompressor{name: name, match: magicMatcher(m), open: open, size: len(m)}
+
+	decompressorsLock.Lock()
+	defer decompressorsLock.Unlock()
+	for i := range decompressors {
+		if decompressors[i].name == name {
+			decompressors[i] = d
+			return
+		}
+	}
+	decompressors = append(decompressors, d)
+}
+
+/*
+Takes a stream and works out from its first few bytes whether it needs to be
+decompressed. Returns a reader of the plain data and a closer for the
+decompressor, which will be nil when the data was not compressed.
+*/
+func detectCompression(in io.Reader) (io.Reader, io.Closer, error) {
+	decompressorsLock.RLock()
+	candidates := make([]decompressor, len(decompressors))
+	copy(candidates, decompressors)
+	decompressorsLock.RUnlock()
+
+	var size int
+	for _, d := range candidates {
+		if d.size > size {
+			size = d.size
+		}
+	}
+
+	buffered := bufio.NewReader(in)
+	// A short read just means a small (or empty) stream, the matchers check
+	// their own lengths so we can ignore the error here and let the scanner
+	// report it later.
+	header, _ := buffered.Peek(size)
+
+	for _, d := range candidates {
+		if d.match(header) {
+			r, err := d.open(buffered)
+			if err != nil {
+				return nil, nil, err
+			}
+			return r, r, nil
+		}
+	}
+	return buffered, nil, nil
+}
+
+func magicMatcher(magic []byte) func([]byte) bool {
+	return func(header []byte) bool {
+		return len(magic) > 0 && bytes.HasPrefix(header, magic)
+	}
+}
+
+// zlibTrialSize is how much of a possible zlib stream is test inflated.
+const zlibTrialSize = 512
+
+/*
+zlib streams don't have a fixed magic number. The first byte holds the
+compression method (8 for deflate) and window size, and the first two bytes
+taken together must be a multiple of 31. Plenty of plain text passes that,
+"80000" at the start of an MPCORB record for one, so the header must not ask
+for a preset dictionary and the start of the stream has to inflate without
+error as well.
+*/
+func isZlibHeader(header []byte) bool {
+	if len(header) < 2 {
+		return false
+	}
+	cmf := header[0]
+	flg := header[1]
+	if cmf&0x0f != 8 || cmf>>4 > 7 || (uint16(cmf)<<8|uint16(flg))%31 != 0 || flg&0x20 != 0 {
+		return false
+	}
+	r, err := zlib.NewReader(bytes.NewReader(header))
+	if err != nil {
+		return false
+	}
+	// Running out of input is fine, we only have the start of the stream.
+	_, err = io.Copy(ioutil.Discard, r)
+	return err == nil || err == io.ErrUnexpectedEOF
+}
+
+func openGzip(in io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(in)
+}
+
+func openBzip2(in io.Reader) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bzip2.NewReader(in)), nil
+}
@@ -0,0 +1,157 @@
+package gompcreader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const samplePath = "testdata/MPCORB_sample.DAT"
+
+func readAllIDs(t *testing.T, reader *MpcReader) []string {
+	var ids []string
+	result, err := reader.ReadEntry()
+	for err == nil {
+		ids = append(ids, result.ID)
+		result, err = reader.ReadEntry()
+	}
+	assert.Equal(t, io.EOF, err)
+	return ids
+}
+
+func sampleBytes(t *testing.T) []byte {
+	data, err := ioutil.ReadFile(samplePath)
+	if err != nil {
+		t.Fatalf("could not read %s: %s", samplePath, err)
+	}
+	return data
+}
+
+func gzipBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func writeTemp(t *testing.T, name string, data []byte) string {
+	dir, err := ioutil.TempDir("", "gompcreader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+var expectedSampleIDs = []string{"1", "5154 T-3"}
+
+func TestDetectGzipWithoutSuffix(t *testing.T) {
+	path := writeTemp(t, "MPCORB.DAT", gzipBytes(sampleBytes(t)))
+	defer os.RemoveAll(filepath.Dir(path))
+
+	reader, err := NewMpcReader(path)
+	assert.Nil(t, err)
+	defer reader.Close()
+	assert.Equal(t, expectedSampleIDs, readAllIDs(t, reader))
+}
+
+func TestPlainFileWithGzipSuffix(t *testing.T) {
+	path := writeTemp(t, "MPCORB.DAT.gz", sampleBytes(t))
+	defer os.RemoveAll(filepath.Dir(path))
+
+	reader, err := NewMpcReader(path)
+	assert.Nil(t, err)
+	defer reader.Close()
+	assert.Equal(t, expectedSampleIDs, readAllIDs(t, reader))
+}
+
+func TestDetectBzip2(t *testing.T) {
+	reader, err := NewMpcReader(samplePath + ".bz2")
+	assert.Nil(t, err)
+	defer reader.Close()
+	assert.Equal(t, expectedSampleIDs, readAllIDs(t, reader))
+}
+
+func TestDetectZlib(t *testing.T) {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(sampleBytes(t))
+	w.Close()
+
+	reader, err := NewMpcReaderFromReader(&buf)
+	assert.Nil(t, err)
+	defer reader.Close()
+	assert.Equal(t, expectedSampleIDs, readAllIDs(t, reader))
+}
+
+func TestPlainRecordLooksLikeZlib(t *testing.T) {
+	// "80" is a valid zlib header, but this isn't compressed
+	line := "80000" + ceresLine[5:]
+	reader, err := NewMpcReaderFromReader(strings.NewReader(line + "\n"))
+	assert.Nil(t, err)
+	defer reader.Close()
+	assert.Equal(t, []string{"80000"}, readAllIDs(t, reader))
+}
+
+func TestEmptyInput(t *testing.T) {
+	reader, err := NewMpcReaderFromReader(strings.NewReader(""))
+	assert.Nil(t, err)
+	defer reader.Close()
+	_, err = reader.ReadEntry()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestRegisterDecompressor(t *testing.T) {
+	// A fake format that is just the magic bytes followed by plain data.
+	magic := []byte{0xfd, 'T', 'S', 'T'}
+	var opened bool
+	RegisterDecompressor("test", magic, func(in io.Reader) (io.ReadCloser, error) {
+		opened = true
+		_, err := io.ReadFull(in, make([]byte, len(magic)))
+		return ioutil.NopCloser(in), err
+	})
+
+	data := append(append([]byte{}, magic...), sampleBytes(t)...)
+	reader, err := NewMpcReaderFromReader(bytes.NewReader(data))
+	assert.Nil(t, err)
+	defer reader.Close()
+	assert.True(t, opened, "registered decompressor was not used")
+	assert.Equal(t, expectedSampleIDs, readAllIDs(t, reader))
+}
+
+var zlibHeaderTests = []struct {
+	in  []byte
+	out bool
+}{
+	{[]byte{0x78, 0x9c}, true},
+	{[]byte{0x78, 0x01}, true},
+	{[]byte{0x78, 0xda}, true},
+	{[]byte("00"), false},
+	{[]byte("MI"), false},
+	{[]byte{0x78}, false},
+	// plain text that passes the header checksum
+	{[]byte("80"), false},
+	{[]byte("x "), false},
+	{[]byte("(4"), false},
+	{[]byte("80000    3.34  0.12 K13B4  10.55761"), false},
+	{[]byte("HK13B4  10.55761   72.29213   80.32762"), false},
+	{[]byte("8O of the elements in the notes section"), false},
+}
+
+func TestIsZlibHeader(t *testing.T) {
+	for _, tt := range zlibHeaderTests {
+		assert.Equal(t, tt.out, isZlibHeader(tt.in), "isZlibHeader(%v)", tt.in)
+	}
+}
@@ -0,0 +1,490 @@
+package gompcreader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// tildeOffset is the first number written in the "~xxxx" form
+	tildeOffset = 620000
+	// MaxPackedNumber is the largest minor planet number that can be packed
+	MaxPackedNumber = tildeOffset + 62*62*62*62 - 1
+	// extendedCycleStart is the first cycle count written in the "_" form
+	extendedCycleStart = 620
+)
+
+// cycleLetters are the letters used for the second letter of a provisional
+// designation. I is skipped to avoid confusion with 1.
+const cycleLetters = "ABCDEFGHJKLMNOPQRSTUVWXYZ"
+
+/*
+DesignationKind identifies which of the MPC designation schemes a Designation
+uses.
+*/
+type DesignationKind int
+
+const (
+	// DesignationNumbered is a permanently numbered object, "(1) Ceres"
+	DesignationNumbered DesignationKind = iota + 1
+	// DesignationProvisional is a provisional designation, "2014 AB123"
+	DesignationProvisional
+	// DesignationSurvey is one of the Palomar-Leiden or Trojan survey
+	// designations, "5154 T-3"
+	DesignationSurvey
+	// DesignationExtended is a provisional designation with a cycle count
+	// above 619 which uses the extended "_OA004S" packed form.
+	DesignationExtended
+)
+
+func (k DesignationKind) String() string {
+	switch k {
+	case DesignationNumbered:
+		return "Numbered"
+	case DesignationProvisional:
+		return "Provisional"
+	case DesignationSurvey:
+		return "Survey"
+	case DesignationExtended:
+		return "Extended"
+	}
+	return "Unknown"
+}
+
+/*
+Designation is a decoded minor planet designation.
+
+Which fields are set depends on the Kind. Numbered designations only have
+Number. Provisional and extended designations have Year, HalfMonth, Letter and
+Cycle, so "2014 AB123" is Year 2014, HalfMonth 'A', Letter 'B' and Cycle 123.
+Survey designations have Number set to the survey number and Survey set to
+one of "P-L", "T-1", "T-2" or "T-3".
+*/
+type Designation struct {
+	Kind      DesignationKind
+	Number    int64
+	Year      int
+	HalfMonth byte
+	Letter    byte
+	Cycle     int
+	Survey    string
+}
+
+/*
+ParseDesignation reads a readable designation such as "1", "(1) Ceres",
+"2014 AB123" or "5154 T-3".
+*/
+func ParseDesignation(designation string) (Designation, error) {
+	packed, err := PackDesignation(designation)
+	if err != nil {
+		return Designation{}, err
+	}
+	return ParsePackedDesignation(packed)
+}
+
+/*
+ParsePackedDesignation reads a packed designation such as "00001", "K14AC3B",
+"_OA004S" or "T3S5154".
+*/
+func ParsePackedDesignation(packed string) (Designation, error) {
+	var d Designation
+	p := strings.TrimSpace(packed)
+	readable, err := UnpackDesignation(p)
+	if err != nil {
+		return d, err
+	}
+
+	parts := strings.SplitN(readable, " ", 2)
+	switch {
+	case len(parts) == 1:
+		d.Kind = DesignationNumbered
+		d.Number, _ = strconv.ParseInt(parts[0], 10, 64)
+	case len(parts[1]) == 3 && parts[1][1] == '-':
+		d.Kind = DesignationSurvey
+		d.Number, _ = strconv.ParseInt(parts[0], 10, 64)
+		d.Survey = parts[1]
+	default:
+		d.Kind = DesignationProvisional
+		if p[0] == '_' {
+			d.Kind = DesignationExtended
+		}
+		d.Year, _ = strconv.Atoi(parts[0])
+		d.HalfMonth = parts[1][0]
+		d.Letter = parts[1][1]
+		if len(parts[1]) > 2 {
+			d.Cycle, _ = strconv.Atoi(parts[1][2:])
+		}
+	}
+	return d, nil
+}
+
+/*
+String returns the readable form of the designation, the same as the ID field
+of a MinorPlanet.
+*/
+func (d Designation) String() string {
+	switch d.Kind {
+	case DesignationNumbered:
+		return strconv.FormatInt(d.Number, 10)
+	case DesignationSurvey:
+		return fmt.Sprintf("%d %s", d.Number, d.Survey)
+	case DesignationProvisional, DesignationExtended:
+		if d.Cycle > 0 {
+			return fmt.Sprintf("%d %c%c%d", d.Year, d.HalfMonth, d.Letter, d.Cycle)
+		}
+		return fmt.Sprintf("%d %c%c", d.Year, d.HalfMonth, d.Letter)
+	}
+	return ""
+}
+
+/*
+Packed returns the packed form of the designation as used in the MPC files.
+*/
+func (d Designation) Packed() (string, error) {
+	return PackDesignation(d.String())
+}
+
+/*
+IsNumbered is a helper for Kind == DesignationNumbered
+*/
+func (d Designation) IsNumbered() bool {
+	return d.Kind == DesignationNumbered
+}
+
+/*
+Splits the readable designation column into its parts. The column holds a
+number in brackets if the object is numbered, followed by either its name or
+its provisional designation, "(1) Ceres", "(3708) 1974 FV1" or "2014 AB123".
+
+Names can contain digits and brackets so anything after the number that isn't
+a valid provisional or survey designation is treated as a name.
+*/
+func splitReadableDesignation(readable string) (number int64, name string, provisional string) {
+	rest := strings.TrimSpace(readable)
+	if strings.HasPrefix(rest, "(") {
+		end := strings.Index(rest, ")")
+		if end > 1 && isDigits(rest[1:end]) {
+			number, _ = strconv.ParseInt(rest[1:end], 10, 64)
+			rest = strings.TrimSpace(rest[end+1:])
+		}
+	}
+	if rest == "" {
+		return number, "", ""
+	}
+	if isProvisionalDesignation(rest) {
+		return number, "", rest
+	}
+	return number, rest, ""
+}
+
+/*
+The inverse of splitReadableDesignation
+*/
+func joinReadableDesignation(number int64, name string, provisional string) string {
+	var parts []string
+	if number > 0 {
+		parts = append(parts, fmt.Sprintf("(%d)", number))
+	}
+	if name != "" {
+		parts = append(parts, name)
+	} else if provisional != "" {
+		parts = append(parts, provisional)
+	}
+	return strings.Join(parts, " ")
+}
+
+/*
+Checks for a provisional or survey designation. This also allows the old
+style "A899 OF" designations used for objects found before 1925.
+*/
+func isProvisionalDesignation(s string) bool {
+	if len(s) >= 7 && s[0] == 'A' && isDigits(s[1:4]) && s[4] == ' ' &&
+		isUpper(s[5]) && isUpper(s[6]) && (len(s) == 7 || isDigits(s[7:])) {
+		return true
+	}
+	d, err := ParseDesignation(s)
+	return err == nil && d.Kind != DesignationNumbered
+}
+
+/*
+DesignationError is returned when a designation can not be packed or unpacked.
+It wraps ErrInvalidDesignation so callers can check for it with errors.Is.
+*/
+type DesignationError struct {
+	// Designation is the input that was rejected
+	Designation string
+	// Reason describes why it couldn't be converted
+	Reason string
+}
+
+func (e *DesignationError) Error() string {
+	return fmt.Sprintf("invalid designation %q: %s", e.Designation, e.Reason)
+}
+
+/*
+Unwrap returns ErrInvalidDesignation
+*/
+func (e *DesignationError) Unwrap() error {
+	return ErrInvalidDesignation
+}
+
+func designationError(designation string, reason string) error {
+	return &DesignationError{Designation: designation, Reason: reason}
+}
+
+/*
+PackDesignation converts a readable designation into the packed form used in
+the MPC files.
+
+This handles numbered objects ("1" or "(1) Ceres") up to MaxPackedNumber,
+including the "~0000" form used above 619,999, provisional designations
+("2014 AB123", or "_OA004S" style when the cycle count is above 619) and the Palomar-Leiden and Trojan survey designations
+("2040 P-L", "3138 T-1", "5154 T-3").
+
+If the designation can't be represented a *DesignationError is returned.
+*/
+func PackDesignation(designation string) (string, error) {
+	d := strings.TrimSpace(designation)
+	if strings.HasPrefix(d, "(") {
+		end := strings.Index(d, ")")
+		if end < 0 {
+			return "", designationError(designation, "unbalanced brackets")
+		}
+		d = d[1:end]
+	}
+	packed, err := packIdentifier(d)
+	if err != nil {
+		if de, ok := err.(*DesignationError); ok {
+			de.Designation = designation
+		}
+		return "", err
+	}
+	return packed, nil
+}
+
+/*
+UnpackDesignation converts a packed designation, as found in the first seven
+columns of an MPCORB record, into its readable form. For example "00001"
+becomes "1", "K14AC3B" becomes "2014 AB123" and "T3S5154" becomes "5154 T-3".
+
+Unlike the reader this checks the packed form strictly and returns a
+*DesignationError for anything it doesn't recognise.
+*/
+func UnpackDesignation(packed string) (string, error) {
+	p := strings.TrimSpace(packed)
+	if !isPackedDesignation(p) {
+		return "", designationError(packed, "unrecognised packed format")
+	}
+	id, err := readPackedIdentifier(p)
+	if err != nil {
+		return "", designationError(packed, err.Error())
+	}
+	return id, nil
+}
+
+/*
+Checks the packed form is one of the layouts readPackedIdentifier understands.
+*/
+func isPackedDesignation(p string) bool {
+	switch len(p) {
+	case 5:
+		// extended numbers, "~0000"
+		if p[0] == '~' {
+			for i := 1; i < len(p); i++ {
+				if !isPackedDigit(p[i]) {
+					return false
+				}
+			}
+			return true
+		}
+		// numbered, "00001" or "A0001"
+		return isPackedDigit(p[0]) && isDigits(p[1:])
+	case 7:
+		// extended provisional, "_OA004S"
+		if p[0] == '_' {
+			for i := 3; i < len(p); i++ {
+				if !isPackedDigit(p[i]) {
+					return false
+				}
+			}
+			return isPackedDigit(p[1]) && isHalfMonth(p[2])
+		}
+		switch p[0:3] {
+		case "PLS", "T1S", "T2S", "T3S":
+			return isDigits(p[3:])
+		}
+		// provisional, "K14AC3B"
+		return isPackedDigit(p[0]) && isDigits(p[1:3]) && isUpper(p[3]) &&
+			isPackedDigit(p[4]) && isDigits(p[5:6]) && isUpper(p[6])
+	}
+	return false
+}
+
+/*
+Reads a packed comet provisional designation such as "K20F030", which is
+"2020 F3". The last character is "0" or a lower case fragment letter, so
+"J96J01b" is "1996 J1-B". Comets that were first given a minor planet style
+designation end in an upper case letter and are read as one.
+*/
+func readPackedCometDesignation(packed string) (string, error) {
+	if packed == "" {
+		return "", nil
+	}
+	if len(packed) != 7 || !isPackedDigit(packed[0]) || !isDigits(packed[1:3]) ||
+		!isHalfMonth(packed[3]) || !isPackedDigit(packed[4]) || !isDigits(packed[5:6]) {
+		return "", designationError(packed, "unrecognised comet designation")
+	}
+	last := packed[6]
+	if isUpper(last) {
+		return readPackedIdentifier(packed)
+	}
+	if last != '0' && (last < 'a' || last > 'z') {
+		return "", designationError(packed, "unrecognised comet fragment")
+	}
+	result := fmt.Sprintf("%d %c%d", readPackedInt(packed[0:3]), packed[3], readPackedInt(packed[4:6]))
+	if last != '0' {
+		result = result + "-" + strings.ToUpper(string(last))
+	}
+	return result, nil
+}
+
+/*
+Packs a number into a single character using 0-9A-Za-z to cover 0 to 61. The
+inverse of the first character handled by readPackedInt.
+*/
+func packDigit(v int64) (byte, bool) {
+	switch {
+	case v < 0:
+		return 0, false
+	case v < 10:
+		return byte('0' + v), true
+	case v < 36:
+		return byte('A' + v - 10), true
+	case v < 62:
+		return byte('a' + v - 36), true
+	}
+	return 0, false
+}
+
+/*
+The inverse of readPackedIdentifier. Takes an unpacked identifier such as
+"1", "1995 XA45" or "5154 T-3" and returns the packed form.
+*/
+func packIdentifier(id string) (string, error) {
+	if isDigits(id) {
+		n, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return "", designationError(id, "number is too large")
+		}
+		if n >= tildeOffset {
+			return packTildeNumber(id, n)
+		}
+		first, _ := packDigit(n / 10000)
+		return fmt.Sprintf("%c%04d", first, n%10000), nil
+	}
+
+	parts := strings.SplitN(id, " ", 2)
+	if len(parts) != 2 || len(parts[0]) != 4 || !isDigits(parts[0]) {
+		return "", designationError(id, "unrecognised format")
+	}
+	head, tail := parts[0], parts[1]
+
+	// survey designations, "5154 T-3"
+	if len(tail) == 3 && tail[1] == '-' {
+		switch tail {
+		case "P-L", "T-1", "T-2", "T-3":
+			return fmt.Sprintf("%c%cS%s", tail[0], tail[2], head), nil
+		}
+		return "", designationError(id, "unknown survey "+tail)
+	}
+
+	// provisional designations, "1995 XA45"
+	if len(tail) < 2 || !isHalfMonth(tail[0]) || strings.IndexByte(cycleLetters, tail[1]) < 0 {
+		return "", designationError(id, "unrecognised format")
+	}
+	year, _ := strconv.ParseInt(head, 10, 64)
+	century, ok := packDigit(year / 100)
+	if !ok {
+		return "", designationError(id, "year can not be packed")
+	}
+	var cycle int64
+	if len(tail) > 2 {
+		if !isDigits(tail[2:]) {
+			return "", designationError(id, "unrecognised format")
+		}
+		cycle, _ = strconv.ParseInt(tail[2:], 10, 64)
+	}
+	if cycle >= extendedCycleStart {
+		return packExtendedProvisional(id, year, tail[0], tail[1], cycle)
+	}
+	cycleHigh, _ := packDigit(cycle / 10)
+	return fmt.Sprintf("%c%02d%c%c%d%c", century, year%100, tail[0], cycleHigh, cycle%10, tail[1]), nil
+}
+
+/*
+Packs numbers of 620,000 and above as a tilde followed by four base 62 digits.
+*/
+func packTildeNumber(id string, n int64) (string, error) {
+	if n > MaxPackedNumber {
+		return "", designationError(id, "number is too large to pack")
+	}
+	result := []byte("~0000")
+	v := n - tildeOffset
+	for i := len(result) - 1; i > 0; i-- {
+		result[i], _ = packDigit(v % 62)
+		v = v / 62
+	}
+	return string(result), nil
+}
+
+/*
+The inverse of readExtendedProvisional
+*/
+func packExtendedProvisional(id string, year int64, halfMonth byte, letter byte, cycle int64) (string, error) {
+	yearDigit, ok := packDigit(year - 2000)
+	if !ok {
+		return "", designationError(id, "year can not be packed in the extended form")
+	}
+	count := (cycle-extendedCycleStart)*int64(len(cycleLetters)) + int64(strings.IndexByte(cycleLetters, letter))
+	if count >= 62*62*62*62 {
+		return "", designationError(id, "cycle count is too large to pack")
+	}
+	result := []byte{'_', yearDigit, halfMonth, '0', '0', '0', '0'}
+	for i := len(result) - 1; i > 2; i-- {
+		result[i], _ = packDigit(count % 62)
+		count = count / 62
+	}
+	return string(result), nil
+}
+
+/*
+Helper to check a string is made entirely of the digits 0-9. Unlike
+onlyNumbers this doesn't allow spaces or an empty string.
+*/
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isPackedDigit(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z')
+}
+
+/*
+Half months are the letters A to Y, skipping I.
+*/
+func isHalfMonth(b byte) bool {
+	return b >= 'A' && b <= 'Y' && b != 'I'
+}
+
+func isUpper(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
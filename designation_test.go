@@ -0,0 +1,293 @@
+package gompcreader
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var packDesignationTests = []stringTestCase{
+	{"2040 P-L", "PLS2040"},
+	{"3138 T-1", "T1S3138"},
+	{"1234 T-2", "T2S1234"},
+	{"5154 T-3", "T3S5154"},
+	{"1995 XA", "J95X00A"},
+	{"1995 XA45", "J95X45A"},
+	{"2014 AB123", "K14AC3B"},
+	{"100001", "A0001"},
+	{"54", "00054"},
+	{"1", "00001"},
+	{"(1) Ceres", "00001"},
+	{" (433) Eros ", "00433"},
+	{"(100001)", "A0001"},
+}
+
+func TestPackDesignation(t *testing.T) {
+	stringTest(t,
+		"PackDesignation",
+		packDesignationTests,
+		func(c string) string {
+			r, e := PackDesignation(c)
+			assert.Nil(t, e)
+			return r
+		})
+}
+
+var packDesignationErrorTests = []string{
+	"", "fish", "1995 xa", "2040 P-Q", "95 XA", "1995 XA4B", "-1", "(1 Ceres", "1995 XA620",
+}
+
+func TestPackDesignationErrors(t *testing.T) {
+	for _, tt := range packDesignationErrorTests {
+		_, e := PackDesignation(tt)
+		var de *DesignationError
+		assert.True(t, errors.As(e, &de), "PackDesignation(%q) returned %v", tt, e)
+		assert.True(t, errors.Is(e, ErrInvalidDesignation))
+		if de != nil {
+			assert.Equal(t, tt, de.Designation)
+		}
+	}
+}
+
+var unpackDesignationTests = []stringTestCase{
+	{"PLS2040", "2040 P-L"},
+	{"T1S3138", "3138 T-1"},
+	{"J95X00A", "1995 XA"},
+	{"J95X45A", "1995 XA45"},
+	{"A0001", "100001"},
+	{" 00054 ", "54"},
+}
+
+func TestUnpackDesignation(t *testing.T) {
+	for _, tt := range packDesignationTests {
+		packed, err := PackDesignation(tt.in)
+		assert.Nil(t, err)
+		r, err := UnpackDesignation(packed)
+		assert.Nil(t, err, "UnpackDesignation(%q)", packed)
+		expected, _ := readPackedIdentifier(tt.out)
+		assert.Equal(t, expected, r)
+	}
+	for _, tt := range unpackDesignationTests {
+		r, err := UnpackDesignation(tt.in)
+		assert.Nil(t, err, "UnpackDesignation(%q)", tt.in)
+		assert.Equal(t, tt.out, r)
+	}
+}
+
+var unpackDesignationErrorTests = []string{"", "0001", "J95X", "XXS1234", "J95x00A", "J9AX00A", "0000A", "T3S51a4"}
+
+func TestUnpackDesignationErrors(t *testing.T) {
+	for _, tt := range unpackDesignationErrorTests {
+		_, e := UnpackDesignation(tt)
+		var de *DesignationError
+		assert.True(t, errors.As(e, &de), "UnpackDesignation(%q) returned %v", tt, e)
+	}
+}
+
+var tildeNumberTests = []stringTestCase{
+	{"619999", "z9999"},
+	{"620000", "~0000"},
+	{"620001", "~0001"},
+	{"620061", "~000z"},
+	{"620062", "~0010"},
+	{"3140113", "~AZaz"},
+	{"15396335", "~zzzz"},
+}
+
+func TestTildeNumbers(t *testing.T) {
+	for _, tt := range tildeNumberTests {
+		packed, err := PackDesignation(tt.in)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.out, packed, "PackDesignation(%s)", tt.in)
+
+		unpacked, err := UnpackDesignation(tt.out)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.in, unpacked, "UnpackDesignation(%s)", tt.out)
+
+		id, err := readPackedIdentifier(tt.out + "  ")
+		assert.Nil(t, err)
+		assert.Equal(t, tt.in, id, "readPackedIdentifier(%s)", tt.out)
+	}
+	assert.Equal(t, int64(15396335), int64(MaxPackedNumber))
+}
+
+func TestTildeNumberErrors(t *testing.T) {
+	_, err := PackDesignation("15396336")
+	assert.True(t, errors.Is(err, ErrInvalidDesignation))
+
+	for _, tt := range []string{"~000", "~00-0", "~0000 1"} {
+		_, err = readPackedIdentifier(tt)
+		assert.NotNil(t, err, "readPackedIdentifier(%q)", tt)
+		_, err = UnpackDesignation(tt)
+		assert.NotNil(t, err, "UnpackDesignation(%q)", tt)
+	}
+}
+
+func TestReadTildeRecord(t *testing.T) {
+	line := "~0000" + ceresLine[5:]
+	result, err := ParseRecord(line)
+	assert.Nil(t, err)
+	assert.Equal(t, "620000", result.ID)
+
+	formatted, err := FormatRecord(result)
+	assert.Nil(t, err)
+	assert.Equal(t, line, formatted)
+}
+
+var extendedProvisionalTests = []stringTestCase{
+	{"2024 AA620", "_OA0000"},
+	{"2024 AB631", "_OA004S"},
+	{"2025 YZ620", "_PY000O"},
+	{"2023 XH1000", "_NX02TL"},
+}
+
+func TestExtendedProvisional(t *testing.T) {
+	for _, tt := range extendedProvisionalTests {
+		packed, err := PackDesignation(tt.in)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.out, packed, "PackDesignation(%s)", tt.in)
+
+		unpacked, err := UnpackDesignation(tt.out)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.in, unpacked, "UnpackDesignation(%s)", tt.out)
+
+		id, err := readPackedIdentifier(tt.out)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.in, id, "readPackedIdentifier(%s)", tt.out)
+	}
+
+	// the last cycle that still fits the normal form
+	packed, err := PackDesignation("2024 AB619")
+	assert.Nil(t, err)
+	assert.Equal(t, "K24Az9B", packed)
+}
+
+var badProvisionalTests = []string{"_OI0000", "_OA00-0", "_OA000", "K23A!1B", "K23a01B", "XXS1234", "!0001", " 0001"}
+
+func TestUnrecognisedDesignations(t *testing.T) {
+	for _, tt := range badProvisionalTests {
+		_, err := readPackedIdentifier(tt)
+		assert.True(t, errors.Is(err, ErrInvalidDesignation) || errors.Is(err, ErrFieldWidth),
+			"readPackedIdentifier(%q) returned %v", tt, err)
+		_, err = UnpackDesignation(tt)
+		assert.True(t, errors.Is(err, ErrInvalidDesignation), "UnpackDesignation(%q) returned %v", tt, err)
+	}
+
+	_, err := PackDesignation("1999 AA620")
+	assert.True(t, errors.Is(err, ErrInvalidDesignation), "years before 2000 can't use the extended form")
+}
+
+var parseDesignationTests = []struct {
+	in     string
+	out    Designation
+	packed string
+}{
+	{"(1) Ceres", Designation{Kind: DesignationNumbered, Number: 1}, "00001"},
+	{"620000", Designation{Kind: DesignationNumbered, Number: 620000}, "~0000"},
+	{"1995 XA", Designation{Kind: DesignationProvisional, Year: 1995, HalfMonth: 'X', Letter: 'A'}, "J95X00A"},
+	{"2014 AB123", Designation{Kind: DesignationProvisional, Year: 2014, HalfMonth: 'A', Letter: 'B', Cycle: 123}, "K14AC3B"},
+	{"2024 AB631", Designation{Kind: DesignationExtended, Year: 2024, HalfMonth: 'A', Letter: 'B', Cycle: 631}, "_OA004S"},
+	{"5154 T-3", Designation{Kind: DesignationSurvey, Number: 5154, Survey: "T-3"}, "T3S5154"},
+	{"2040 P-L", Designation{Kind: DesignationSurvey, Number: 2040, Survey: "P-L"}, "PLS2040"},
+}
+
+func TestParseDesignation(t *testing.T) {
+	for _, tt := range parseDesignationTests {
+		d, err := ParseDesignation(tt.in)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.out, d, "ParseDesignation(%s)", tt.in)
+
+		d, err = ParsePackedDesignation(tt.packed)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.out, d, "ParsePackedDesignation(%s)", tt.packed)
+
+		packed, err := d.Packed()
+		assert.Nil(t, err)
+		assert.Equal(t, tt.packed, packed)
+
+		expected, _ := UnpackDesignation(tt.packed)
+		assert.Equal(t, expected, d.String())
+		assert.Equal(t, tt.out.Kind == DesignationNumbered, d.IsNumbered())
+	}
+
+	_, err := ParseDesignation("fish")
+	assert.True(t, errors.Is(err, ErrInvalidDesignation))
+	assert.Equal(t, "", Designation{}.String())
+}
+
+func TestMinorPlanetDesignation(t *testing.T) {
+	result, err := ParseRecord(ceresLine)
+	assert.Nil(t, err)
+	assert.Equal(t, Designation{Kind: DesignationNumbered, Number: 1}, result.Designation)
+	assert.Equal(t, result.ID, result.Designation.String())
+
+	result, err = ParseRecord(t3s5154Line)
+	assert.Nil(t, err)
+	assert.Equal(t, DesignationSurvey, result.Designation.Kind)
+	assert.Equal(t, result.ID, result.Designation.String())
+}
+
+var splitReadableDesignationTests = []struct {
+	in          string
+	number      int64
+	name        string
+	provisional string
+}{
+	{"(1) Ceres", 1, "Ceres", ""},
+	{"     (1) Ceres              ", 1, "Ceres", ""},
+	{"(3708) 1974 FV1", 3708, "", "1974 FV1"},
+	{"(2309) Mr. Spock", 2309, "Mr. Spock", ""},
+	{"(4015) Wilson-Harrington", 4015, "Wilson-Harrington", ""},
+	{"(9999) 2001 Odyssey (test)", 9999, "2001 Odyssey (test)", ""},
+	{"(719) Albert", 719, "Albert", ""},
+	{"(1000) A899 OF", 1000, "", "A899 OF"},
+	{"5154 T-3", 0, "", "5154 T-3"},
+	{"2014 AB123", 0, "", "2014 AB123"},
+	{"(12345)", 12345, "", ""},
+	{"", 0, "", ""},
+}
+
+func TestSplitReadableDesignation(t *testing.T) {
+	for _, tt := range splitReadableDesignationTests {
+		number, name, provisional := splitReadableDesignation(tt.in)
+		assert.Equal(t, tt.number, number, "number of %q", tt.in)
+		assert.Equal(t, tt.name, name, "name of %q", tt.in)
+		assert.Equal(t, tt.provisional, provisional, "provisional designation of %q", tt.in)
+
+		assert.Equal(t, strings.TrimSpace(tt.in), joinReadableDesignation(number, name, provisional))
+	}
+}
+
+func TestMinorPlanetNames(t *testing.T) {
+	result, err := ParseRecord(ceresLine)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), result.Number)
+	assert.Equal(t, "Ceres", result.Name)
+	assert.Equal(t, "", result.ProvisionalDesignation)
+
+	result, err = ParseRecord(t3s5154Line)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), result.Number)
+	assert.Equal(t, "", result.Name)
+	assert.Equal(t, "5154 T-3", result.ProvisionalDesignation)
+}
+
+func TestFormatRecordNames(t *testing.T) {
+	p, _ := ParseRecord(ceresLine)
+	p.Name = "Demeter"
+	formatted, err := FormatRecord(p)
+	assert.Nil(t, err)
+
+	result, err := ParseRecord(formatted)
+	assert.Nil(t, err)
+	assert.Equal(t, "(1) Demeter", result.ReadableDesignation)
+	assert.Equal(t, "Demeter", result.Name)
+
+	// with no split fields the raw column is used
+	p = &MinorPlanet{ID: "1", ReadableDesignation: "(1) Ceres", DateOfLastObservation: p.DateOfLastObservation}
+	formatted, err = FormatRecord(p)
+	assert.Nil(t, err)
+	assert.Equal(t, ceresLine[166:194], formatted[166:194])
+}
@@ -0,0 +1,94 @@
+package gompcreader
+
+import (
+	"math"
+)
+
+// jupiterSemimajorAxis is Jupiter's mean semimajor axis at J2000.0 in AU, from
+// the same Standish elements as earthElements.
+const jupiterSemimajorAxis = 5.20288700
+
+/*
+PerihelionDistance returns q = a(1 - e), the closest the orbit comes to the
+Sun, in AU. Hyperbolic orbits have a negative semimajor axis, so a and e
+that would give a q of zero or less, such as e >= 1 with a positive a, don't
+describe an orbit and the result is NaN.
+*/
+func (p *MinorPlanet) PerihelionDistance() float64 {
+	q := p.SemimajorAxis * (1 - p.OrbitalEccentricity)
+	if !(q > 0) {
+		return math.NaN()
+	}
+	return q
+}
+
+/*
+AphelionDistance returns Q = a(1 + e), the furthest the orbit goes from the
+Sun, in AU. This is infinite for orbits that aren't elliptic, and NaN for an
+elliptic orbit without a positive semimajor axis.
+*/
+func (p *MinorPlanet) AphelionDistance() float64 {
+	if p.OrbitalEccentricity >= 1 {
+		return math.Inf(1)
+	}
+	if !(p.SemimajorAxis > 0) {
+		return math.NaN()
+	}
+	return p.SemimajorAxis * (1 + p.OrbitalEccentricity)
+}
+
+/*
+Period returns the orbital period in days, worked out from MeanDailyMotion.
+If the mean daily motion is zero it is worked out from the semimajor axis
+instead, and if that isn't positive either the result is NaN. This is
+infinite for orbits that aren't elliptic.
+
+A float is used rather than a time.Duration as the periods of the most
+distant objects are longer than a time.Duration can hold.
+*/
+func (p *MinorPlanet) Period() float64 {
+	if p.OrbitalEccentricity >= 1 {
+		return math.Inf(1)
+	}
+	n := p.MeanDailyMotion
+	if n == 0 {
+		if p.SemimajorAxis <= 0 {
+			return math.NaN()
+		}
+		n = p.KeplerianMeanDailyMotion()
+	}
+	return 360 / n
+}
+
+/*
+KeplerianMeanDailyMotion returns the mean daily motion, in degrees per day,
+that SemimajorAxis gives by Kepler's third law. Comparing it with
+MeanDailyMotion is a quick consistency check on a record.
+*/
+func (p *MinorPlanet) KeplerianMeanDailyMotion() float64 {
+	return degrees(gaussianGravitationalConstant / math.Pow(p.SemimajorAxis, 1.5))
+}
+
+/*
+TisserandJupiter returns the Tisserand parameter with respect to Jupiter,
+aJ/a + 2 cos(i) sqrt((a/aJ)(1 - e^2)). Values above 3 are typical of
+asteroids and values between 2 and 3 of Jupiter family comets. It is NaN
+when PerihelionDistance is, as the elements don't describe an orbit.
+*/
+func (p *MinorPlanet) TisserandJupiter() float64 {
+	if math.IsNaN(p.PerihelionDistance()) {
+		return math.NaN()
+	}
+	a := p.SemimajorAxis
+	e := p.OrbitalEccentricity
+	return jupiterSemimajorAxis/a +
+		2*math.Cos(radians(p.InclinationToTheEcliptic))*math.Sqrt(a/jupiterSemimajorAxis*(1-e*e))
+}
+
+/*
+LongitudeOfPerihelion returns the sum of the longitude of the ascending node
+and the argument of perihelion, in degrees between 0 and 360.
+*/
+func (p *MinorPlanet) LongitudeOfPerihelion() float64 {
+	return math.Mod(p.LongitudeOfTheAscendingNode+p.ArgumentOfPerihelion, 360)
+}
@@ -0,0 +1,70 @@
+package gompcreader
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var derivedElementsTests = []struct {
+	line         string
+	perihelion   float64
+	aphelion     float64
+	period       float64
+	tisserand    float64
+	longitude    float64
+	keplerMotion float64
+}{
+	{ceresLine, 2.557090777, 2.976523823, 1680.996461, 3.309950931, 152.61975, 0.214158691},
+	{t3s5154Line, 2.239473439, 3.944466761, 1985.872393, 3.143926393, 352.20181, 0.181280523},
+}
+
+func TestDerivedElements(t *testing.T) {
+	for _, tt := range derivedElementsTests {
+		p, err := ParseRecord(tt.line)
+		assert.Nil(t, err)
+		assert.InDelta(t, tt.perihelion, p.PerihelionDistance(), 1e-9, p.ID)
+		assert.InDelta(t, tt.aphelion, p.AphelionDistance(), 1e-9, p.ID)
+		assert.InDelta(t, tt.period, p.Period(), 1e-6, p.ID)
+		assert.InDelta(t, tt.tisserand, p.TisserandJupiter(), 1e-9, p.ID)
+		assert.InDelta(t, tt.longitude, p.LongitudeOfPerihelion(), 1e-9, p.ID)
+		assert.InDelta(t, tt.keplerMotion, p.KeplerianMeanDailyMotion(), 1e-9, p.ID)
+		// the file's mean motion agrees with its semimajor axis
+		assert.InDelta(t, p.MeanDailyMotion, p.KeplerianMeanDailyMotion(), 1e-7, p.ID)
+	}
+}
+
+func TestDerivedElementsEdgeCases(t *testing.T) {
+	p := &MinorPlanet{SemimajorAxis: 1, OrbitalEccentricity: 0}
+	// one AU gives one sidereal year
+	assert.InDelta(t, 365.2569, p.Period(), 1e-3)
+
+	p = &MinorPlanet{SemimajorAxis: 2, OrbitalEccentricity: 1.1}
+	assert.True(t, math.IsInf(p.AphelionDistance(), 1))
+	assert.True(t, math.IsInf(p.Period(), 1))
+	// a positive a with e >= 1 isn't an orbit
+	assert.True(t, math.IsNaN(p.PerihelionDistance()))
+	assert.True(t, math.IsNaN(p.TisserandJupiter()))
+	p = &MinorPlanet{SemimajorAxis: 2, OrbitalEccentricity: 1}
+	assert.True(t, math.IsNaN(p.PerihelionDistance()))
+	assert.True(t, math.IsNaN(p.TisserandJupiter()))
+
+	// hyperbolic orbits have a negative a
+	p = &MinorPlanet{SemimajorAxis: -2, OrbitalEccentricity: 1.5, InclinationToTheEcliptic: 90}
+	assert.InDelta(t, 1, p.PerihelionDistance(), 1e-12)
+	assert.InDelta(t, jupiterSemimajorAxis/-2, p.TisserandJupiter(), 1e-12)
+
+	// with neither n nor a there is no period
+	p = &MinorPlanet{}
+	assert.True(t, math.IsNaN(p.Period()))
+	assert.True(t, math.IsNaN(p.AphelionDistance()))
+
+	// an ellipse needs a positive a
+	p = &MinorPlanet{SemimajorAxis: -2, OrbitalEccentricity: 0.5}
+	assert.True(t, math.IsNaN(p.AphelionDistance()))
+	assert.True(t, math.IsNaN(p.PerihelionDistance()))
+
+	p = &MinorPlanet{LongitudeOfTheAscendingNode: 300, ArgumentOfPerihelion: 100}
+	assert.InDelta(t, 40, p.LongitudeOfPerihelion(), 1e-12)
+}
@@ -0,0 +1,230 @@
+package gompcreader
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/emilyselwood/gompcreader/photometry"
+)
+
+// speedOfLight in AU per day
+const speedOfLight = 173.1446326846693
+
+/*
+EphemerisRow is the predicted position and brightness of a minor planet at a
+single time.
+
+RightAscension and Declination are astrometric J2000 values in degrees.
+Delta is the distance from the observer and Distance the distance from the
+Sun, both in AU. Elongation is the angle between the Sun and the object seen
+from the observer, Phase is the angle between the Sun and the observer seen
+from the object, both in degrees. Magnitude is the predicted V magnitude, or
+NaN if the minor planet has no absolute magnitude.
+*/
+type EphemerisRow struct {
+	Time           time.Time
+	RightAscension float64
+	Declination    float64
+	Delta          float64
+	Distance       float64
+	Elongation     float64
+	Phase          float64
+	Magnitude      float64
+}
+
+/*
+Ephemeris works out where the minor planet will appear from the observer at
+each of the times.
+
+The minor planet is propagated with PositionAt and corrected for light time.
+The Earth's position comes from a low precision model, Standish's Keplerian
+elements for the Earth-Moon barycentre with a simple lunar theory to get from
+the barycentre to the geocentre. Between 1800 and 2050 that puts the Earth
+within about 15,000 km of where it really is, so the direction to an object
+can be wrong by about 20 arc seconds divided by its distance in AU. That is
+fine for planning observations of most objects but isn't precise enough for
+astrometry, and for very close approaches the error can grow to degrees.
+
+If observer is nil the positions are geocentric. Times are UTC, like the
+times of observations, and the rows keep them as they were given. They are
+converted to TT for the orbits, while the Earth's rotation uses UTC in place
+of UT1, which it is always within a second of.
+*/
+func Ephemeris(p *MinorPlanet, times []time.Time, observer *Observatory) ([]EphemerisRow, error) {
+	result := make([]EphemerisRow, 0, len(times))
+	for _, t := range times {
+		row, err := ephemerisAt(p, t, observer)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, nil
+}
+
+func ephemerisAt(p *MinorPlanet, t time.Time, observer *Observatory) (EphemerisRow, error) {
+	tt := terrestrialTime(t)
+	observerPosition := earthPosition(tt)
+	if observer != nil {
+		offset, err := observer.PositionAt(t)
+		if err != nil {
+			return EphemerisRow{}, err
+		}
+		observerPosition = observerPosition.Add(EquatorialToEcliptic(offset))
+	}
+
+	// Work back to when the light we see left the object. This converges to
+	// well under a millisecond in a few iterations.
+	var lightTime float64
+	var target, rho Vector
+	for i := 0; i < 3; i++ {
+		sv, err := p.PositionAt(tt.Add(-time.Duration(lightTime * 24 * float64(time.Hour))))
+		if err != nil {
+			return EphemerisRow{}, err
+		}
+		target = sv.Position
+		rho = target.Sub(observerPosition)
+		lightTime = rho.Norm() / speedOfLight
+	}
+
+	equatorial := EclipticToEquatorial(rho)
+	delta := rho.Norm()
+	r := target.Norm()
+	sun := observerPosition.Norm()
+
+	row := EphemerisRow{
+		Time:           t,
+		RightAscension: math.Mod(degrees(math.Atan2(equatorial.Y, equatorial.X))+360, 360),
+		Declination:    degrees(math.Asin(equatorial.Z / delta)),
+		Delta:          delta,
+		Distance:       r,
+		Elongation:     degrees(math.Acos(clamp((sun*sun + delta*delta - r*r) / (2 * sun * delta)))),
+		Phase:          degrees(math.Acos(clamp((r*r + delta*delta - sun*sun) / (2 * r * delta)))),
+	}
+	row.Magnitude = math.NaN()
+	if model, ok := p.Photometry(); ok {
+		row.Magnitude = photometry.ApparentMagnitude(model, r, delta, row.Phase)
+	}
+	return row, nil
+}
+
+func clamp(x float64) float64 {
+	return math.Max(-1, math.Min(1, x))
+}
+
+/*
+Standish's approximate Keplerian elements for the Earth-Moon barycentre,
+valid 1800-2050, from https://ssd.jpl.nasa.gov/planets/approx_pos.html
+
+Each element is a value at J2000.0 and a rate per Julian century.
+*/
+var earthElements = struct {
+	a, e, i, l, peri, node [2]float64
+}{
+	a:    [2]float64{1.00000261, 0.00000562},
+	e:    [2]float64{0.01671123, -0.00004392},
+	i:    [2]float64{-0.00001531, -0.01294668},
+	l:    [2]float64{100.46457166, 35999.37244981},
+	peri: [2]float64{102.93768193, 0.32327364},
+	node: [2]float64{0, 0},
+}
+
+// moonMassFraction is the Moon's share of the mass of the Earth-Moon system.
+const moonMassFraction = 1 / (1 + 81.30056)
+
+/*
+Returns the heliocentric position of the Earth in AU in the J2000 ecliptic
+frame at t in TT. The barycentre is up to about 4,700 km from the geocentre, so the
+Moon's pull is taken off.
+*/
+func earthPosition(t time.Time) Vector {
+	return barycentrePosition(t).Sub(moonPosition(t).Scale(moonMassFraction))
+}
+
+/*
+Returns the heliocentric position of the Earth-Moon barycentre in AU in the
+J2000 ecliptic frame.
+*/
+func barycentrePosition(t time.Time) Vector {
+	el := earthElements
+	d := daysSinceJ2000(t)
+	c := d / 36525
+	at := func(x [2]float64) float64 { return x[0] + x[1]*c }
+
+	a := at(el.a)
+	e := at(el.e)
+	peri := at(el.peri)
+	node := at(el.node)
+	n := radians(el.l[1]) / 36525
+	m := radians(math.Mod(at(el.l)-peri, 360))
+
+	// This can't fail, the eccentricity is tiny
+	sv, _ := conicState(a*(1-e), e, n, m/n, peri-node, node, at(el.i))
+	return sv.Position
+}
+
+/*
+Returns the geocentric position of the Moon in AU in the J2000 ecliptic frame.
+This is the low precision formula from the Astronomical Almanac, good to about
+0.3 degrees, which is plenty for the barycentre offset where it is scaled down
+by the Moon's share of the mass. The longitude is moved from the equinox of
+date to J2000 with the general precession.
+*/
+func moonPosition(t time.Time) Vector {
+	c := daysSinceJ2000(t) / 36525
+	sin := func(a, b float64) float64 { return math.Sin(radians(a + b*c)) }
+	cos := func(a, b float64) float64 { return math.Cos(radians(a + b*c)) }
+
+	longitude := 218.32 + 481267.881*c +
+		6.29*sin(135.0, 477198.87) - 1.27*sin(259.3, -413335.36) +
+		0.66*sin(235.7, 890534.22) + 0.21*sin(269.9, 954397.74) -
+		0.19*sin(357.5, 35999.05) - 0.11*sin(186.5, 966404.03)
+	latitude := 5.13*sin(93.3, 483202.02) + 0.28*sin(228.2, 960400.89) -
+		0.28*sin(318.3, 6003.15) - 0.17*sin(217.6, -407332.21)
+	parallax := 0.9508 +
+		0.0518*cos(135.0, 477198.87) + 0.0095*cos(259.3, -413335.36) +
+		0.0078*cos(235.7, 890534.22) + 0.0028*cos(269.9, 954397.74)
+
+	longitude = radians(longitude - 1.396971*c)
+	latitude = radians(latitude)
+	r := earthEquatorialRadius / math.Sin(radians(parallax)) / AstronomicalUnit
+	return Vector{
+		X: r * math.Cos(latitude) * math.Cos(longitude),
+		Y: r * math.Cos(latitude) * math.Sin(longitude),
+		Z: r * math.Sin(latitude),
+	}
+}
+
+/*
+FormatEphemeris writes the rows as a table in the same layout as the MPC
+ephemeris service.
+*/
+func FormatEphemeris(w io.Writer, rows []EphemerisRow) error {
+	if _, err := fmt.Fprintln(w, "Date       UT      R.A. (J2000) Decl.    Delta     r     Elong. Phase   V"); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(w, row.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+String formats the row as a single line of FormatEphemeris output.
+*/
+func (row EphemerisRow) String() string {
+	t := row.Time.UTC()
+	magnitude := "     "
+	if !math.IsNaN(row.Magnitude) {
+		magnitude = fmt.Sprintf("%5.1f", row.Magnitude)
+	}
+	return fmt.Sprintf("%s %s %s %7.3f %7.3f %6.1f %5.1f %s",
+		t.Format("2006 01 02 150405"),
+		formatSexagesimal(row.RightAscension/15, 1, false),
+		formatSexagesimal(row.Declination, 0, true),
+		row.Delta, row.Distance, row.Elongation, row.Phase, magnitude)
+}
@@ -0,0 +1,158 @@
+package gompcreader
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEarthPosition(t *testing.T) {
+	// The geocentre at J2000.0 from JPL DE405. The barycentre on its own is
+	// nearly 7,000 km away.
+	j2000 := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+	de405 := Vector{X: -0.1771350993, Y: 0.9672416868, Z: -0.0000040853}
+	earth := earthPosition(j2000)
+	assert.True(t, earth.Sub(de405).Norm()*AstronomicalUnit < 2500)
+	assert.True(t, barycentrePosition(j2000).Sub(de405).Norm()*AstronomicalUnit > 5000)
+
+	// perihelion and aphelion in 2024
+	assert.InDelta(t, 0.98331, earthPosition(time.Date(2024, 1, 3, 0, 39, 0, 0, time.UTC)).Norm(), 1e-4)
+	assert.InDelta(t, 1.01673, earthPosition(time.Date(2024, 7, 5, 5, 6, 0, 0, time.UTC)).Norm(), 1e-4)
+}
+
+func TestMoonPosition(t *testing.T) {
+	// Meeus, Astronomical Algorithms example 47.a, moved to J2000
+	moon := moonPosition(time.Date(1992, 4, 12, 0, 0, 0, 0, time.UTC))
+	assert.InDelta(t, 133.2705, degrees(math.Atan2(moon.Y, moon.X)), 0.3)
+	assert.InDelta(t, -3.2291, degrees(math.Asin(moon.Z/moon.Norm())), 0.3)
+	assert.InDelta(t, 368409.7, moon.Norm()*AstronomicalUnit, 1000)
+
+	// the geocentre is on the far side of the barycentre from the Moon
+	when := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	offset := earthPosition(when).Sub(barycentrePosition(when))
+	assert.InDelta(t, -1, offset.Dot(moonPosition(when))/offset.Norm()/moonPosition(when).Norm(), 1e-12)
+	assert.True(t, offset.Norm()*AstronomicalUnit > 4200 && offset.Norm()*AstronomicalUnit < 4900)
+}
+
+func TestEphemerisCeresOpposition(t *testing.T) {
+	ceres, err := ParseRecord(ceresLine)
+	assert.Nil(t, err)
+
+	var times []time.Time
+	for day := 1; day <= 30; day++ {
+		times = append(times, time.Date(2014, 4, day, 0, 0, 0, 0, time.UTC))
+	}
+	rows, err := Ephemeris(ceres, times, nil)
+	assert.Nil(t, err)
+	assert.Len(t, rows, len(times))
+
+	// Ceres was at opposition on 2014 April 15 in Virgo, at magnitude 7.0. The
+	// greatest elongation isn't quite at opposition as Ceres is north of the
+	// ecliptic.
+	best := rows[0]
+	for _, row := range rows {
+		if row.Elongation > best.Elongation {
+			best = row
+		}
+	}
+	assert.WithinDuration(t, time.Date(2014, 4, 15, 12, 0, 0, 0, time.UTC), best.Time, 36*time.Hour)
+	assert.InDelta(t, 13.9*15, best.RightAscension, 1)
+	assert.InDelta(t, 3.5, best.Declination, 1)
+	assert.InDelta(t, 1.64, best.Delta, 0.01)
+	assert.InDelta(t, 2.63, best.Distance, 0.01)
+	assert.InDelta(t, 7.0, best.Magnitude, 0.1)
+	assert.True(t, best.Elongation > 160 && best.Phase < 7)
+	assert.InDelta(t, 180, best.Elongation+best.Phase, 10)
+}
+
+func TestEphemerisObserver(t *testing.T) {
+	ceres, err := ParseRecord(ceresLine)
+	assert.Nil(t, err)
+	list := readSampleObservatories(t)
+	times := []time.Time{time.Date(2014, 4, 15, 10, 0, 0, 0, time.UTC)}
+
+	geocentric, err := Ephemeris(ceres, times, nil)
+	assert.Nil(t, err)
+	centre, err := Ephemeris(ceres, times, list["500"])
+	assert.Nil(t, err)
+	assert.Equal(t, geocentric, centre)
+
+	// from Mauna Kea parallax moves Ceres by a few arc seconds
+	topocentric, err := Ephemeris(ceres, times, list["568"])
+	assert.Nil(t, err)
+	shift := math.Hypot(
+		(topocentric[0].RightAscension-geocentric[0].RightAscension)*math.Cos(radians(geocentric[0].Declination)),
+		topocentric[0].Declination-geocentric[0].Declination) * 3600
+	assert.True(t, shift > 0.5 && shift < 6, "parallax shift %g arc seconds", shift)
+
+	_, err = Ephemeris(ceres, times, list["C51"])
+	assert.True(t, errors.Is(err, ErrNoObservatoryPosition))
+}
+
+func TestEphemerisCloseApproach(t *testing.T) {
+	// An object on the Earth's orbit about 70,000 km ahead of it, closer than
+	// Apophis will pass in 2029. Using TT for the sidereal time would move it
+	// by arc minutes, and the barycentre in place of the geocentre by degrees.
+	j2000 := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+	p := &MinorPlanet{
+		Epoch:                    j2000,
+		MeanAnomalyEpoch:         -2.45,
+		ArgumentOfPerihelion:     102.93768193,
+		InclinationToTheEcliptic: 0.01,
+		OrbitalEccentricity:      0.01671123,
+		SemimajorAxis:            1.00000261,
+	}
+	list := readSampleObservatories(t)
+	times := []time.Time{j2000, j2000.Add(time.Hour)}
+
+	geocentric, err := Ephemeris(p, times, nil)
+	assert.Nil(t, err)
+	assert.InDelta(t, 200.1948673, geocentric[0].RightAscension, 1e-6)
+	assert.InDelta(t, 14.7039437, geocentric[0].Declination, 1e-6)
+	assert.InDelta(t, 70369, geocentric[0].Delta*AstronomicalUnit, 1)
+
+	topocentric, err := Ephemeris(p, times, list["568"])
+	assert.Nil(t, err)
+	assert.InDelta(t, 205.1833735, topocentric[0].RightAscension, 1e-6)
+	assert.InDelta(t, 13.2376440, topocentric[0].Declination, 1e-6)
+	assert.InDelta(t, 204.8400700, topocentric[1].RightAscension, 1e-6)
+	assert.InDelta(t, 13.5035458, topocentric[1].Declination, 1e-6)
+	assert.Equal(t, times[1], topocentric[1].Time)
+}
+
+func TestEphemerisLightTime(t *testing.T) {
+	ceres, err := ParseRecord(ceresLine)
+	assert.Nil(t, err)
+	when := time.Date(2014, 4, 15, 0, 0, 0, 0, time.UTC)
+	rows, err := Ephemeris(ceres, []time.Time{when}, nil)
+	assert.Nil(t, err)
+
+	// The object should be where it was when the light left it
+	lightTime := time.Duration(rows[0].Delta / speedOfLight * 24 * float64(time.Hour))
+	sv, err := ceres.PositionAt(terrestrialTime(when).Add(-lightTime))
+	assert.Nil(t, err)
+	assert.InDelta(t, sv.Position.Norm(), rows[0].Distance, 1e-9)
+}
+
+func TestFormatEphemeris(t *testing.T) {
+	rows := []EphemerisRow{{
+		Time:           time.Date(2014, 4, 15, 0, 0, 0, 0, time.UTC),
+		RightAscension: (13 + 53.0/60 + 9.52/3600) * 15,
+		Declination:    -(3 + 33.0/60 + 44.6/3600),
+		Delta:          1.6439,
+		Distance:       2.6266,
+		Elongation:     166.04,
+		Phase:          5.21,
+		Magnitude:      6.97,
+	}}
+	var buf bytes.Buffer
+	assert.Nil(t, FormatEphemeris(&buf, rows))
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "2014 04 15 000000 13 53 09.5 -03 33 45   1.644   2.627  166.0   5.2   7.0", lines[1])
+}
@@ -0,0 +1,79 @@
+package gompcreader
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrShortRecord is wrapped in a ParseError when a record ends before the
+// field being read.
+var ErrShortRecord = errors.New("record is too short for field")
+
+// ErrFieldWidth is wrapped in a ParseError when a field doesn't have the
+// number of characters its encoding needs, for example a packed date that
+// isn't five characters long.
+var ErrFieldWidth = errors.New("field is not the expected width")
+
+// ErrInvalidDesignation is returned, wrapped in a DesignationError, when a
+// designation can not be converted to or from its packed form.
+var ErrInvalidDesignation = errors.New("invalid designation")
+
+// ErrUnknownObservatory is returned when an observatory code isn't in the list
+// of observatories.
+var ErrUnknownObservatory = errors.New("unknown observatory code")
+
+// ErrNoObservatoryPosition is returned when a position is asked for from an
+// observatory without parallax constants, such as a space telescope.
+var ErrNoObservatoryPosition = errors.New("observatory has no fixed position")
+
+// ErrKeplerConvergence is returned when Kepler's equation could not be solved.
+var ErrKeplerConvergence = errors.New("kepler's equation did not converge")
+
+// ErrUnsupportedOrbit is returned when the orbital elements can't be
+// propagated, for example a minor planet with an eccentricity of 1 or more.
+var ErrUnsupportedOrbit = errors.New("orbit can not be propagated")
+
+/*
+ParseError is returned when a record can not be converted into a MinorPlanet.
+
+It records where in the input the problem was found and which field could not
+be read. The underlying error, usually from the strconv package, is available
+through Unwrap so errors.Is and errors.As can still be used to inspect it.
+*/
+type ParseError struct {
+	// Line is the 1-based line number of the record in the input. This is zero
+	// when the record did not come from an MpcReader.
+	Line int64
+	// Offset is the byte offset of the start of the record in the decompressed
+	// input. Like Line this is only set by an MpcReader.
+	Offset int64
+	// Field is the name of the MinorPlanet field that could not be read.
+	Field string
+	// StartColumn and EndColumn are the 1-based, inclusive, column range of the
+	// field as used in the MPC format documentation. These are zero for formats
+	// that aren't fixed width, such as ADES.
+	StartColumn int
+	EndColumn   int
+	// Record is the raw line that was being converted.
+	Record string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	field := e.Field
+	if e.StartColumn > 0 {
+		field = fmt.Sprintf("%s (columns %d-%d)", e.Field, e.StartColumn, e.EndColumn)
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, field, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", field, e.Err)
+}
+
+/*
+Unwrap returns the underlying error that caused the field to be rejected.
+*/
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
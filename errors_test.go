@@ -0,0 +1,96 @@
+package gompcreader
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrorUnwrap(t *testing.T) {
+	line := "00001    3.34  0.12 K13B4  10.55761   72.29213  jajhkhs  hfdsjkgjkh"
+	_, err := convertToMinorPlanet(line)
+
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe), "expected a *ParseError got %T", err)
+	assert.Equal(t, "LongitudeOfTheAscendingNode", pe.Field)
+	assert.Equal(t, 49, pe.StartColumn)
+	assert.Equal(t, 57, pe.EndColumn)
+	assert.Equal(t, line, pe.Record)
+	assert.True(t, errors.Is(err, strconv.ErrSyntax), "errors.Is should reach the strconv cause")
+
+	var numErr *strconv.NumError
+	assert.True(t, errors.As(err, &numErr))
+	assert.Equal(t, "ParseFloat", numErr.Func)
+}
+
+func TestReadEntryParseErrorPosition(t *testing.T) {
+	// break the eccentricity column of the second record
+	broken := t3s5154Line[:70] + "abcdefghi" + t3s5154Line[79:]
+	first := "header line\n"
+	input := first + ceresLine + "\n" + broken + "\n"
+
+	reader, err := NewMpcReaderFromReader(strings.NewReader(input))
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	_, err = reader.ReadEntry()
+	assert.Nil(t, err)
+
+	_, err = reader.ReadEntry()
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe), "expected a *ParseError got %T", err)
+	assert.Equal(t, int64(3), pe.Line)
+	assert.Equal(t, int64(len(first)+len(ceresLine)+1), pe.Offset)
+	assert.Equal(t, "OrbitalEccentricity", pe.Field)
+	assert.Equal(t, broken, pe.Record)
+	assert.Equal(t,
+		"line 3: OrbitalEccentricity (columns 71-79): strconv.ParseFloat: parsing \"abcdefghi\": invalid syntax",
+		pe.Error())
+}
+
+func brokenInput() string {
+	broken := t3s5154Line[:70] + "abcdefghi" + t3s5154Line[79:]
+	return "header line\n" + ceresLine + "\n" + broken + "\n\n" + t3s5154Line + "\n"
+}
+
+func TestErrorModeStrict(t *testing.T) {
+	reader, err := NewMpcReaderFromReader(strings.NewReader(brokenInput()))
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	_, err = reader.ReadEntry()
+	assert.Nil(t, err)
+	_, err = reader.ReadEntry()
+	assert.NotNil(t, err)
+	assert.Equal(t, ReaderStats{Read: 1, NonRecordLines: 1, Rejected: 1}, reader.Stats())
+}
+
+func TestErrorModeSkip(t *testing.T) {
+	reader, err := NewMpcReaderFromReader(strings.NewReader(brokenInput()))
+	assert.Nil(t, err)
+	defer reader.Close()
+	reader.SetErrorMode(ErrorModeSkip)
+
+	assert.Equal(t, []string{"1", "5154 T-3"}, readAllIDs(t, reader))
+	assert.Equal(t, ReaderStats{Read: 2, NonRecordLines: 2, Rejected: 1}, reader.Stats())
+	assert.Empty(t, reader.Rejections())
+}
+
+func TestErrorModeCollect(t *testing.T) {
+	reader, err := NewMpcReaderFromReader(strings.NewReader(brokenInput()))
+	assert.Nil(t, err)
+	defer reader.Close()
+	reader.SetErrorMode(ErrorModeCollect)
+
+	assert.Equal(t, []string{"1", "5154 T-3"}, readAllIDs(t, reader))
+	assert.Equal(t, ReaderStats{Read: 2, NonRecordLines: 2, Rejected: 1}, reader.Stats())
+
+	rejections := reader.Rejections()
+	assert.Len(t, rejections, 1)
+	assert.Equal(t, int64(3), rejections[0].Line)
+	assert.Equal(t, "OrbitalEccentricity", rejections[0].Field)
+	assert.Equal(t, t3s5154Line[:70]+"abcdefghi"+t3s5154Line[79:], rejections[0].Record)
+}
@@ -0,0 +1,118 @@
+package gompcreader
+
+/*
+Flags decodes the four hex digit flags in columns 162-165 of an MPCORB record.
+
+The bottom six bits hold the orbit type and the top five bits mark NEOs, PHAs
+and a couple of MPC list memberships. The remaining bits are reserved for
+internal MPC use.
+*/
+type Flags int64
+
+const (
+	flagOrbitTypeMask        Flags = 0x003f
+	flagNEO                  Flags = 0x0800
+	flagOneKmNEO             Flags = 0x1000
+	flagOneOppositionEarlier Flags = 0x2000
+	flagCriticalList         Flags = 0x4000
+	flagPHA                  Flags = 0x8000
+)
+
+/*
+OrbitType is the dynamical class stored in the bottom six bits of the flags.
+*/
+type OrbitType int
+
+// The orbit types as defined in the MPCORB format documentation. Main belt
+// and other objects that don't fit a class are OrbitTypeUnclassified.
+const (
+	OrbitTypeUnclassified  OrbitType = 0
+	OrbitTypeAtira         OrbitType = 1
+	OrbitTypeAten          OrbitType = 2
+	OrbitTypeApollo        OrbitType = 3
+	OrbitTypeAmor          OrbitType = 4
+	OrbitTypeMarsCrosser   OrbitType = 5
+	OrbitTypeHungaria      OrbitType = 6
+	OrbitTypeInternal      OrbitType = 7
+	OrbitTypeHilda         OrbitType = 8
+	OrbitTypeJupiterTrojan OrbitType = 9
+	OrbitTypeDistant       OrbitType = 10
+)
+
+func (o OrbitType) String() string {
+	switch o {
+	case OrbitTypeUnclassified:
+		return "Unclassified"
+	case OrbitTypeAtira:
+		return "Atira"
+	case OrbitTypeAten:
+		return "Aten"
+	case OrbitTypeApollo:
+		return "Apollo"
+	case OrbitTypeAmor:
+		return "Amor"
+	case OrbitTypeMarsCrosser:
+		return "Mars-crosser"
+	case OrbitTypeHungaria:
+		return "Hungaria"
+	case OrbitTypeInternal:
+		return "Internal"
+	case OrbitTypeHilda:
+		return "Hilda"
+	case OrbitTypeJupiterTrojan:
+		return "Jupiter Trojan"
+	case OrbitTypeDistant:
+		return "Distant object"
+	}
+	return "Unknown"
+}
+
+/*
+Flags returns the decoded form of HexDigitFlags
+*/
+func (p *MinorPlanet) Flags() Flags {
+	return Flags(p.HexDigitFlags)
+}
+
+/*
+OrbitType returns the orbit class from the bottom six bits
+*/
+func (f Flags) OrbitType() OrbitType {
+	return OrbitType(f & flagOrbitTypeMask)
+}
+
+/*
+IsNEO is true for near earth objects
+*/
+func (f Flags) IsNEO() bool {
+	return f&flagNEO != 0
+}
+
+/*
+IsOneKmNEO is true for near earth objects of 1km or larger
+*/
+func (f Flags) IsOneKmNEO() bool {
+	return f&flagOneKmNEO != 0
+}
+
+/*
+IsOneOppositionEarlier is true for one opposition objects that were also seen
+at an earlier opposition
+*/
+func (f Flags) IsOneOppositionEarlier() bool {
+	return f&flagOneOppositionEarlier != 0
+}
+
+/*
+IsCriticalList is true for numbered objects on the critical list
+*/
+func (f Flags) IsCriticalList() bool {
+	return f&flagCriticalList != 0
+}
+
+/*
+IsPHA is true for potentially hazardous asteroids
+*/
+func (f Flags) IsPHA() bool {
+	return f&flagPHA != 0
+}
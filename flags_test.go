@@ -0,0 +1,46 @@
+package gompcreader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var flagsTests = []struct {
+	in       string
+	orbit    OrbitType
+	neo      bool
+	oneKm    bool
+	earlier  bool
+	critical bool
+	pha      bool
+}{
+	{"0000", OrbitTypeUnclassified, false, false, false, false, false},
+	{"4804", OrbitTypeAmor, true, false, false, true, false},
+	{"9803", OrbitTypeApollo, true, true, false, false, true},
+	{"2009", OrbitTypeJupiterTrojan, false, false, true, false, false},
+	{"000a", OrbitTypeDistant, false, false, false, false, false},
+	{"0801", OrbitTypeAtira, true, false, false, false, false},
+	{"0046", OrbitTypeHungaria, false, false, false, false, false},
+}
+
+func TestFlags(t *testing.T) {
+	for _, tt := range flagsTests {
+		v, err := readHexInt(tt.in)
+		assert.Nil(t, err)
+		p := MinorPlanet{HexDigitFlags: v}
+		f := p.Flags()
+		assert.Equal(t, tt.orbit, f.OrbitType(), "orbit type of %s", tt.in)
+		assert.Equal(t, tt.neo, f.IsNEO(), "NEO flag of %s", tt.in)
+		assert.Equal(t, tt.oneKm, f.IsOneKmNEO(), "1km NEO flag of %s", tt.in)
+		assert.Equal(t, tt.earlier, f.IsOneOppositionEarlier(), "earlier opposition flag of %s", tt.in)
+		assert.Equal(t, tt.critical, f.IsCriticalList(), "critical list flag of %s", tt.in)
+		assert.Equal(t, tt.pha, f.IsPHA(), "PHA flag of %s", tt.in)
+	}
+}
+
+func TestOrbitTypeString(t *testing.T) {
+	assert.Equal(t, "Apollo", OrbitTypeApollo.String())
+	assert.Equal(t, "Jupiter Trojan", OrbitTypeJupiterTrojan.String())
+	assert.Equal(t, "Unknown", OrbitType(63).String())
+}
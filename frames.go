@@ -0,0 +1,151 @@
+package gompcreader
+
+import (
+	"math"
+	"time"
+)
+
+// AstronomicalUnit is the length of the astronomical unit in km.
+const AstronomicalUnit = 149597870.7
+
+// earthEquatorialRadius is the WGS84 equatorial radius of the Earth in km.
+const earthEquatorialRadius = 6378.137
+
+// j2000Unix is 2000 January 1 12:00, the J2000.0 epoch, as a unix time.
+const j2000Unix = 946728000
+
+/*
+Vector is a cartesian position or velocity. The units and frame depend on
+where it came from, see the functions that return one.
+*/
+type Vector struct {
+	X, Y, Z float64
+}
+
+/*
+Norm returns the length of the vector.
+*/
+func (v Vector) Norm() float64 {
+	return math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+}
+
+/*
+Add returns v + o.
+*/
+func (v Vector) Add(o Vector) Vector {
+	return Vector{X: v.X + o.X, Y: v.Y + o.Y, Z: v.Z + o.Z}
+}
+
+/*
+Sub returns v - o.
+*/
+func (v Vector) Sub(o Vector) Vector {
+	return Vector{X: v.X - o.X, Y: v.Y - o.Y, Z: v.Z - o.Z}
+}
+
+/*
+Scale returns v multiplied by f.
+*/
+func (v Vector) Scale(f float64) Vector {
+	return Vector{X: v.X * f, Y: v.Y * f, Z: v.Z * f}
+}
+
+/*
+Dot returns the dot product of v and o.
+*/
+func (v Vector) Dot(o Vector) float64 {
+	return v.X*o.X + v.Y*o.Y + v.Z*o.Z
+}
+
+// obliquityJ2000 is the obliquity of the ecliptic at J2000.0 in degrees.
+const obliquityJ2000 = 23.4392911
+
+/*
+EclipticToEquatorial rotates a J2000 ecliptic vector into the J2000
+equatorial frame.
+*/
+func EclipticToEquatorial(v Vector) Vector {
+	c, s := math.Cos(radians(obliquityJ2000)), math.Sin(radians(obliquityJ2000))
+	return Vector{X: v.X, Y: c*v.Y - s*v.Z, Z: s*v.Y + c*v.Z}
+}
+
+/*
+EquatorialToEcliptic is the inverse of EclipticToEquatorial.
+*/
+func EquatorialToEcliptic(v Vector) Vector {
+	c, s := math.Cos(radians(obliquityJ2000)), math.Sin(radians(obliquityJ2000))
+	return Vector{X: v.X, Y: c*v.Y + s*v.Z, Z: -s*v.Y + c*v.Z}
+}
+
+/*
+Returns the IAU 1976 precession angles zeta, z and theta, in radians, for the
+mean equator of date t. These are from Meeus, Astronomical Algorithms
+equation 21.3 with J2000.0 as the starting epoch.
+*/
+func precessionAngles(t time.Time) (zeta, z, theta float64) {
+	c := daysSinceJ2000(t) / 36525
+	zeta = (2306.2181*c + 0.30188*c*c + 0.017998*c*c*c) / 3600
+	z = (2306.2181*c + 1.09468*c*c + 0.018203*c*c*c) / 3600
+	theta = (2004.3109*c - 0.42665*c*c - 0.041833*c*c*c) / 3600
+	return radians(zeta), radians(z), radians(theta)
+}
+
+/*
+Rotates an equatorial vector from the J2000 frame to the mean equator and
+equinox of date t.
+*/
+func precessFromJ2000(v Vector, t time.Time) Vector {
+	zeta, z, theta := precessionAngles(t)
+	cx, sx := math.Cos(zeta), math.Sin(zeta)
+	cz, sz := math.Cos(z), math.Sin(z)
+	ct, st := math.Cos(theta), math.Sin(theta)
+	return Vector{
+		X: (cx*cz*ct-sx*sz)*v.X + (-sx*cz*ct-cx*sz)*v.Y - cz*st*v.Z,
+		Y: (cx*sz*ct+sx*cz)*v.X + (-sx*sz*ct+cx*cz)*v.Y - sz*st*v.Z,
+		Z: cx*st*v.X - sx*st*v.Y + ct*v.Z,
+	}
+}
+
+/*
+The inverse of precessFromJ2000, from the mean equator of date t to J2000.
+*/
+func precessToJ2000(v Vector, t time.Time) Vector {
+	zeta, z, theta := precessionAngles(t)
+	cx, sx := math.Cos(zeta), math.Sin(zeta)
+	cz, sz := math.Cos(z), math.Sin(z)
+	ct, st := math.Cos(theta), math.Sin(theta)
+	return Vector{
+		X: (cx*cz*ct-sx*sz)*v.X + (cx*sz*ct+sx*cz)*v.Y + cx*st*v.Z,
+		Y: (-sx*cz*ct-cx*sz)*v.X + (-sx*sz*ct+cx*cz)*v.Y - sx*st*v.Z,
+		Z: -cz*st*v.X - sz*st*v.Y + ct*v.Z,
+	}
+}
+
+/*
+Returns the number of days, including the fraction, between the J2000.0 epoch
+and t. No attempt is made to convert between time scales, t is used as is.
+*/
+func daysSinceJ2000(t time.Time) float64 {
+	return (float64(t.Unix())-j2000Unix)/86400 + float64(t.Nanosecond())/86400e9
+}
+
+/*
+Greenwich mean sidereal time in radians, from Meeus, Astronomical Algorithms
+equation 12.4. t should be UT1, but UTC is always within 0.9 seconds of it,
+which is close enough for the precision we need. TT is not, it is more than a
+minute ahead.
+*/
+func greenwichMeanSiderealTime(t time.Time) float64 {
+	d := daysSinceJ2000(t)
+	c := d / 36525
+	theta := 280.46061837 + 360.98564736629*d + 0.000387933*c*c - c*c*c/38710000
+	return radians(math.Mod(theta, 360))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
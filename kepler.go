@@ -0,0 +1,264 @@
+package gompcreader
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// gaussianGravitationalConstant is k, in radians per day, so that k*k is the
+// gravitational parameter of the Sun in AU^3/day^2.
+const gaussianGravitationalConstant = 0.01720209895
+
+// keplerTolerance is how close the solution to Kepler's equation has to be, in
+// radians.
+const keplerTolerance = 1e-14
+
+const keplerMaxIterations = 50
+
+/*
+StateVector is a position in AU and velocity in AU/day. Those returned by
+PositionAt are heliocentric in the J2000 ecliptic frame, the same frame as the
+MPC orbital elements.
+*/
+type StateVector struct {
+	Position Vector
+	Velocity Vector
+}
+
+/*
+SolveKepler solves Kepler's equation for an orbit with eccentricity e and mean
+anomaly m, in radians.
+
+For elliptic orbits, e < 1, it returns the eccentric anomaly E where
+m = E - e sin E. For hyperbolic orbits, e > 1, it returns the hyperbolic
+anomaly F where m = e sinh F - F. For parabolic orbits, e == 1, m should be
+k t / sqrt(2 q^3), for time since perihelion t in days and perihelion distance
+q, and it returns tan(v/2) for the true anomaly v from Barker's equation
+m = D + D^3 / 3.
+
+The near parabolic cases, where e is close to 1 and m is small, are solved
+without the loss of precision that the textbook form of the equations has.
+
+An error wrapping ErrKeplerConvergence is returned if no solution was found.
+*/
+func SolveKepler(e float64, m float64) (float64, error) {
+	switch {
+	case e < 0 || math.IsNaN(e) || math.IsNaN(m) || math.IsInf(m, 0):
+		return 0, fmt.Errorf("%w: e=%g m=%g", ErrKeplerConvergence, e, m)
+	case e < 1:
+		return solveElliptic(e, m)
+	case e > 1:
+		return solveHyperbolic(e, m)
+	}
+	return solveParabolic(m), nil
+}
+
+/*
+Newton's method on E - e sin E - m, written as (1 - e) E + e (E - sin E) - m
+so that it stays accurate close to e = 1. The starting value is from Danby,
+which converges for every e and m.
+*/
+func solveElliptic(e float64, m float64) (float64, error) {
+	// Reduce m to -pi..pi, the solution is shifted by the same amount
+	turns := math.Floor((m+math.Pi)/(2*math.Pi)) * 2 * math.Pi
+	m -= turns
+
+	x := m + 0.85*e*sign(math.Sin(m))
+	for i := 0; i < keplerMaxIterations; i++ {
+		f := (1-e)*x + e*xMinusSin(x) - m
+		df := 1 - e*math.Cos(x)
+		step := f / df
+		x -= step
+		if math.Abs(step) <= keplerTolerance*math.Max(1, math.Abs(x)) {
+			return x + turns, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: e=%g m=%g", ErrKeplerConvergence, e, m+turns)
+}
+
+/*
+Newton's method on e sinh F - F - m, written in the same way as solveElliptic.
+Danby's starting value again makes this converge for every e and m.
+*/
+func solveHyperbolic(e float64, m float64) (float64, error) {
+	x := sign(m) * math.Log(2*math.Abs(m)/e+1.8)
+	for i := 0; i < keplerMaxIterations; i++ {
+		f := (e-1)*x + e*sinhMinusX(x) - m
+		df := e*math.Cosh(x) - 1
+		step := f / df
+		x -= step
+		if math.Abs(step) <= keplerTolerance*math.Max(1, math.Abs(x)) {
+			return x, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: e=%g m=%g", ErrKeplerConvergence, e, m)
+}
+
+/*
+Barker's equation has a closed form solution.
+*/
+func solveParabolic(m float64) float64 {
+	w := 1.5 * m
+	y := math.Cbrt(w + math.Sqrt(w*w+1))
+	return y - 1/y
+}
+
+/*
+x - sin x, using its series for small x where the subtraction would lose
+precision.
+*/
+func xMinusSin(x float64) float64 {
+	if math.Abs(x) > 0.5 {
+		return x - math.Sin(x)
+	}
+	x2 := x * x
+	term := x * x2 / 6
+	sum := term
+	for n := 2.0; math.Abs(term) > 1e-17*math.Abs(sum); n++ {
+		term *= -x2 / ((2 * n) * (2*n + 1))
+		sum += term
+	}
+	return sum
+}
+
+/*
+sinh x - x, the hyperbolic version of xMinusSin.
+*/
+func sinhMinusX(x float64) float64 {
+	if math.Abs(x) > 0.5 {
+		return math.Sinh(x) - x
+	}
+	x2 := x * x
+	term := x * x2 / 6
+	sum := term
+	for n := 2.0; math.Abs(term) > 1e-17*math.Abs(sum); n++ {
+		term *= x2 / ((2 * n) * (2*n + 1))
+		sum += term
+	}
+	return sum
+}
+
+func sign(x float64) float64 {
+	if x < 0 {
+		return -1
+	}
+	return 1
+}
+
+/*
+Works out the position and velocity in the plane of the orbit, with x towards
+perihelion, for perihelion distance q, eccentricity e and the time since
+perihelion dt in days. n is the mean motion in radians per day for elliptic
+and hyperbolic orbits, it is ignored for parabolic ones.
+
+The equations are arranged around q rather than the semimajor axis so that
+they stay accurate as e gets close to 1.
+*/
+func orbitPlaneState(q float64, e float64, n float64, dt float64) (Vector, Vector, error) {
+	switch {
+	case e < 1:
+		a := q / (1 - e)
+		anomaly, err := SolveKepler(e, n*dt)
+		if err != nil {
+			return Vector{}, Vector{}, err
+		}
+		s := math.Sin(anomaly / 2)
+		r := q + 2*a*e*s*s
+		return Vector{X: q - 2*a*s*s, Y: math.Sqrt(a*q*(1+e)) * math.Sin(anomaly)},
+			Vector{X: -n * a * a * math.Sin(anomaly) / r, Y: n * a * math.Sqrt(a*q*(1+e)) * math.Cos(anomaly) / r},
+			nil
+	case e > 1:
+		a := q / (e - 1)
+		anomaly, err := SolveKepler(e, n*dt)
+		if err != nil {
+			return Vector{}, Vector{}, err
+		}
+		s := math.Sinh(anomaly / 2)
+		r := q + 2*a*e*s*s
+		return Vector{X: q - 2*a*s*s, Y: math.Sqrt(a*q*(1+e)) * math.Sinh(anomaly)},
+			Vector{X: -n * a * a * math.Sinh(anomaly) / r, Y: n * a * math.Sqrt(a*q*(1+e)) * math.Cosh(anomaly) / r},
+			nil
+	}
+	k := gaussianGravitationalConstant
+	d := solveParabolic(k * dt / math.Sqrt(2*q*q*q))
+	v := math.Sqrt(k * k / (2 * q))
+	return Vector{X: q * (1 - d*d), Y: 2 * q * d},
+		Vector{X: -v * 2 * d / (1 + d*d), Y: v * 2 / (1 + d*d)},
+		nil
+}
+
+/*
+Rotates a vector in the plane of the orbit into the ecliptic frame using the
+argument of perihelion, longitude of the ascending node and inclination, all
+in degrees.
+*/
+func orbitToEcliptic(v Vector, peri float64, node float64, incl float64) Vector {
+	cw, sw := math.Cos(radians(peri)), math.Sin(radians(peri))
+	cn, sn := math.Cos(radians(node)), math.Sin(radians(node))
+	ci, si := math.Cos(radians(incl)), math.Sin(radians(incl))
+	return Vector{
+		X: (cw*cn-sw*sn*ci)*v.X + (-sw*cn-cw*sn*ci)*v.Y,
+		Y: (cw*sn+sw*cn*ci)*v.X + (-sw*sn+cw*cn*ci)*v.Y,
+		Z: sw*si*v.X + cw*si*v.Y,
+	}
+}
+
+func conicState(q, e, n, dt, peri, node, incl float64) (StateVector, error) {
+	r, v, err := orbitPlaneState(q, e, n, dt)
+	if err != nil {
+		return StateVector{}, err
+	}
+	return StateVector{
+		Position: orbitToEcliptic(r, peri, node, incl),
+		Velocity: orbitToEcliptic(v, peri, node, incl),
+	}, nil
+}
+
+/*
+PositionAt propagates the osculating elements to t using two body motion and
+returns the heliocentric position and velocity in the J2000 ecliptic frame.
+
+t is treated as TT, the same time scale as the Epoch. The mean daily motion
+from the file is used so the result matches the MPC's own propagation. Two
+body motion ignores the planets, so the further t is from the Epoch the less
+accurate the result.
+
+An error is returned if the elements don't describe an elliptic orbit.
+*/
+func (p *MinorPlanet) PositionAt(t time.Time) (StateVector, error) {
+	e := p.OrbitalEccentricity
+	if e < 0 || e >= 1 || p.SemimajorAxis <= 0 {
+		return StateVector{}, fmt.Errorf("%w: e=%g a=%g", ErrUnsupportedOrbit, e, p.SemimajorAxis)
+	}
+	n := radians(p.MeanDailyMotion)
+	if n == 0 {
+		n = gaussianGravitationalConstant / math.Pow(p.SemimajorAxis, 1.5)
+	}
+	dt := daysSinceJ2000(t) - daysSinceJ2000(p.Epoch)
+	sincePerihelion := dt + radians(p.MeanAnomalyEpoch)/n
+	return conicState(p.SemimajorAxis*(1-e), e, n, sincePerihelion,
+		p.ArgumentOfPerihelion, p.LongitudeOfTheAscendingNode, p.InclinationToTheEcliptic)
+}
+
+/*
+PositionAt returns the heliocentric position and velocity of the comet at t
+in the J2000 ecliptic frame, using two body motion from the perihelion time.
+This works for elliptic, parabolic and hyperbolic orbits.
+
+t is treated as TT, the same as PerihelionTime.
+*/
+func (c *Comet) PositionAt(t time.Time) (StateVector, error) {
+	q := c.PerihelionDistance
+	e := c.OrbitalEccentricity
+	if q <= 0 || e < 0 {
+		return StateVector{}, fmt.Errorf("%w: e=%g q=%g", ErrUnsupportedOrbit, e, q)
+	}
+	var n float64
+	if e != 1 {
+		n = gaussianGravitationalConstant * math.Pow(math.Abs(1-e)/q, 1.5)
+	}
+	dt := daysSinceJ2000(t) - daysSinceJ2000(c.PerihelionTime)
+	return conicState(q, e, n, dt,
+		c.ArgumentOfPerihelion, c.LongitudeOfTheAscendingNode, c.InclinationToTheEcliptic)
+}
@@ -0,0 +1,198 @@
+package gompcreader
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var keplerTests = []struct {
+	e float64
+	m float64
+}{
+	{0, 1},
+	{0.0757973, 0.18426},
+	{0.5, -2},
+	{0.9, 0.001},
+	{0.999999, 1e-6},
+	{0.999999, 3.1},
+	{0.3, 40},
+	{1.000001, 1e-6},
+	{1.5, 0.5},
+	{3, -25},
+	{1.1, 1000},
+}
+
+func TestSolveKepler(t *testing.T) {
+	for _, tt := range keplerTests {
+		x, err := SolveKepler(tt.e, tt.m)
+		assert.Nil(t, err)
+		// check against the equation in a form that doesn't lose precision
+		var m float64
+		if tt.e < 1 {
+			m = (1-tt.e)*x + tt.e*xMinusSin(x)
+		} else {
+			m = (tt.e-1)*x + tt.e*sinhMinusX(x)
+		}
+		assert.InDelta(t, tt.m, m, 1e-12*math.Max(1, math.Abs(tt.m)), "e=%g m=%g", tt.e, tt.m)
+	}
+}
+
+func TestSolveKeplerParabolic(t *testing.T) {
+	for _, d := range []float64{-3, -0.1, 0, 1, 20} {
+		x, err := SolveKepler(1, d+d*d*d/3)
+		assert.Nil(t, err)
+		assert.InDelta(t, d, x, 1e-10*math.Max(1, math.Abs(d)))
+	}
+}
+
+func TestSolveKeplerErrors(t *testing.T) {
+	_, err := SolveKepler(-0.1, 1)
+	assert.True(t, errors.Is(err, ErrKeplerConvergence))
+	_, err = SolveKepler(0.5, math.NaN())
+	assert.True(t, errors.Is(err, ErrKeplerConvergence))
+}
+
+func TestSeries(t *testing.T) {
+	for _, x := range []float64{-0.5, -0.1, 1e-3, 0.2, 0.49} {
+		assert.InDelta(t, x-math.Sin(x), xMinusSin(x), 1e-15)
+		assert.InDelta(t, math.Sinh(x)-x, sinhMinusX(x), 1e-15)
+	}
+}
+
+func readStateVectors(t *testing.T, path string) (times []time.Time, states []StateVector) {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if strings.HasPrefix(s.Text(), "#") {
+			continue
+		}
+		var when string
+		var sv StateVector
+		_, err := fmt.Sscan(s.Text(), &when,
+			&sv.Position.X, &sv.Position.Y, &sv.Position.Z,
+			&sv.Velocity.X, &sv.Velocity.Y, &sv.Velocity.Z)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tt, err := time.Parse(time.RFC3339, when)
+		if err != nil {
+			t.Fatal(err)
+		}
+		times = append(times, tt)
+		states = append(states, sv)
+	}
+	return times, states
+}
+
+func TestMinorPlanetPositionAt(t *testing.T) {
+	ceres, err := ParseRecord(ceresLine)
+	assert.Nil(t, err)
+
+	times, states := readStateVectors(t, "testdata/ceres_vectors.txt")
+	assert.Len(t, times, 6)
+	for i, when := range times {
+		sv, err := ceres.PositionAt(when)
+		assert.Nil(t, err)
+		assert.InDelta(t, states[i].Position.X, sv.Position.X, 1e-9, "x at %s", when)
+		assert.InDelta(t, states[i].Position.Y, sv.Position.Y, 1e-9, "y at %s", when)
+		assert.InDelta(t, states[i].Position.Z, sv.Position.Z, 1e-9, "z at %s", when)
+		assert.InDelta(t, states[i].Velocity.X, sv.Velocity.X, 1e-12, "vx at %s", when)
+		assert.InDelta(t, states[i].Velocity.Y, sv.Velocity.Y, 1e-12, "vy at %s", when)
+		assert.InDelta(t, states[i].Velocity.Z, sv.Velocity.Z, 1e-12, "vz at %s", when)
+	}
+}
+
+func TestMinorPlanetPositionAtConservesEnergy(t *testing.T) {
+	planet, err := ParseRecord(t3s5154Line)
+	assert.Nil(t, err)
+
+	n := radians(planet.MeanDailyMotion)
+	a := planet.SemimajorAxis
+	mu := n * n * a * a * a
+	for _, days := range []float64{0, 17, 365.25, -4000, 20000} {
+		when := planet.Epoch.Add(time.Duration(days * 24 * float64(time.Hour)))
+		sv, err := planet.PositionAt(when)
+		assert.Nil(t, err)
+		v := sv.Velocity.Norm()
+		r := sv.Position.Norm()
+		assert.InDelta(t, -mu/(2*a), v*v/2-mu/r, 1e-14, "energy after %g days", days)
+		assert.True(t, r >= a*(1-planet.OrbitalEccentricity)-1e-12 && r <= a*(1+planet.OrbitalEccentricity)+1e-12)
+	}
+}
+
+func TestMinorPlanetPositionAtErrors(t *testing.T) {
+	_, err := (&MinorPlanet{OrbitalEccentricity: 1.2, SemimajorAxis: 3}).PositionAt(time.Now())
+	assert.True(t, errors.Is(err, ErrUnsupportedOrbit))
+	_, err = (&MinorPlanet{OrbitalEccentricity: 0.1}).PositionAt(time.Now())
+	assert.True(t, errors.Is(err, ErrUnsupportedOrbit))
+}
+
+func TestCometPositionAt(t *testing.T) {
+	perihelion := time.Date(2020, 7, 3, 16, 0, 0, 0, time.UTC)
+	k := gaussianGravitationalConstant
+	for _, e := range []float64{0.5, 0.999249, 1, 1.000001, 2} {
+		c := &Comet{
+			PerihelionTime:              perihelion,
+			PerihelionDistance:          0.294609,
+			OrbitalEccentricity:         e,
+			ArgumentOfPerihelion:        37.2784,
+			LongitudeOfTheAscendingNode: 61.0102,
+			InclinationToTheEcliptic:    128.9375,
+		}
+
+		// at perihelion the comet is q from the sun and moving at right
+		// angles to the sun direction
+		sv, err := c.PositionAt(perihelion)
+		assert.Nil(t, err)
+		assert.InDelta(t, c.PerihelionDistance, sv.Position.Norm(), 1e-12, "e=%g", e)
+		dot := sv.Position.X*sv.Velocity.X + sv.Position.Y*sv.Velocity.Y + sv.Position.Z*sv.Velocity.Z
+		assert.InDelta(t, 0, dot, 1e-14, "e=%g", e)
+
+		// vis-viva holds away from perihelion too
+		for _, days := range []float64{-30, 45, 400} {
+			sv, err = c.PositionAt(perihelion.Add(time.Duration(days * 24 * float64(time.Hour))))
+			assert.Nil(t, err)
+			r := sv.Position.Norm()
+			v := sv.Velocity.Norm()
+			inverseA := (1 - e) / c.PerihelionDistance
+			assert.InDelta(t, k*k*(2/r-inverseA), v*v, 1e-12, "e=%g after %g days", e, days)
+		}
+	}
+}
+
+func TestCometPositionAtNearParabolic(t *testing.T) {
+	c := &Comet{
+		PerihelionTime:              time.Date(2020, 7, 3, 16, 0, 0, 0, time.UTC),
+		PerihelionDistance:          0.294609,
+		OrbitalEccentricity:         1,
+		ArgumentOfPerihelion:        37.2784,
+		LongitudeOfTheAscendingNode: 61.0102,
+		InclinationToTheEcliptic:    128.9375,
+	}
+	when := c.PerihelionTime.Add(60 * 24 * time.Hour)
+	parabolic, err := c.PositionAt(when)
+	assert.Nil(t, err)
+
+	// orbits either side of parabolic should converge on the same position
+	for _, e := range []float64{1 - 1e-9, 1 + 1e-9} {
+		c.OrbitalEccentricity = e
+		sv, err := c.PositionAt(when)
+		assert.Nil(t, err)
+		assert.InDelta(t, parabolic.Position.X, sv.Position.X, 1e-8, "e=%g", e)
+		assert.InDelta(t, parabolic.Position.Y, sv.Position.Y, 1e-8, "e=%g", e)
+		assert.InDelta(t, parabolic.Position.Z, sv.Position.Z, 1e-8, "e=%g", e)
+	}
+}
@@ -5,15 +5,24 @@ data files.
 Designed to load the files from http://www.minorplanetcenter.net/iau/MPCORB.html
 and http://www.minorplanetcenter.net/iau/ECS/MPCAT/MPCAT.html
 
-This will not read the comets files or the observations files. If you try you
-will probably find the first call to ReadEntry() returns io.EOF as it has skipped
-all the records in the file.
-
-This can handle both the gzipped and uncompressed versions of the files. If you
-need speed and don't care about space use the uncompressed versions.
+MpcReader will not read the comets files or the observations files. If you try
+you will probably find the first call to ReadEntry() returns io.EOF as it has
+skipped all the records in the file. Use CometReader for the comet elements
+file, CometEls.txt, and ObservationReader for the 80 column observation files.
+Observations in the newer ADES format can be read with ADESReader (PSV) or
+ReadADESXML. ObservatoryReader reads the list of observatory codes, which can be
+used to find where an observation was made from.
+
+This can handle both the gzipped and uncompressed versions of the files, as well
+as bzip2 and zlib compressed copies. The compression is detected from the
+content rather than the file name. Other formats can be added with
+RegisterDecompressor. If you need speed and don't care about space use the
+uncompressed versions.
 
 To read a file first construct a MpcReader using the NewMpcReader(string) function.
-This requires the path to a file.
+This requires the path to a file. If the data is coming from somewhere other
+than a file, such as stdin or a network stream, use NewMpcReaderFromReader or
+NewMpcReaderFromReadCloser instead.
 
 Each record is read using the ReadEntry() function. Note this may consume more
 than one line from the file if the next line is not the correct length. These
@@ -25,19 +34,11 @@ normally be defered.
 */
 package gompcreader
 
-/**
-	TODO:
-  perterbers translation
-*/
-
 import (
-	"bufio"
 	"bytes"
-	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -45,9 +46,16 @@ import (
 
 /*
 MinorPlanet is the result of reading a record from a file
+
+ReadableDesignation is the raw text of columns 167-194, such as "(1) Ceres".
+It is also split into Number, which is zero for unnumbered objects, Name and
+ProvisionalDesignation. Only one of Name and ProvisionalDesignation is set as
+the file shows the name for named objects and the provisional designation
+otherwise.
 */
 type MinorPlanet struct {
 	ID                           string
+	Designation                  Designation
 	AbsoluteMagnitude            float64
 	Slope                        float64
 	Epoch                        time.Time
@@ -68,10 +76,97 @@ type MinorPlanet struct {
 	ComputerName                 string
 	HexDigitFlags                int64
 	ReadableDesignation          string
+	Number                       int64
+	Name                         string
+	ProvisionalDesignation       string
 	DateOfLastObservation        time.Time
 	YearOfFirstObservation       int64
 	YearOfLastObservation        int64
 	ArcLength                    int64
+
+	// missing records which of the optional columns were blank
+	missing OptionalFields
+}
+
+/*
+OptionalFields is a set of the MinorPlanet, Comet and Observation fields that
+can be blank in the file. A blank column reads as zero, so these are the only
+way to tell a blank column from a real zero.
+
+The zero value means every field has a value, so a record built in code
+behaves as it always has unless SetMissing is called.
+*/
+type OptionalFields uint8
+
+const (
+	// FieldAbsoluteMagnitude is the AbsoluteMagnitude field
+	FieldAbsoluteMagnitude OptionalFields = 1 << iota
+	// FieldSlope is the Slope field, or SlopeParameter of a Comet
+	FieldSlope
+	// FieldNumberOfObservations is the NumberOfObservations field
+	FieldNumberOfObservations
+	// FieldNumberOfOppositions is the NumberOfOppositions field
+	FieldNumberOfOppositions
+	// FieldRMSResidual is the RMSResidual field
+	FieldRMSResidual
+	// FieldMagnitude is the Magnitude field of an Observation
+	FieldMagnitude
+)
+
+/*
+Missing returns the set of optional fields that were blank in the record.
+*/
+func (p *MinorPlanet) Missing() OptionalFields {
+	return p.missing
+}
+
+/*
+SetMissing replaces the set of optional fields that have no value. The
+writer leaves these columns blank whatever the value of the field.
+*/
+func (p *MinorPlanet) SetMissing(fields OptionalFields) {
+	p.missing = fields
+}
+
+/*
+HasAbsoluteMagnitude reports whether the record had an absolute magnitude. If
+it is false AbsoluteMagnitude is zero but that isn't a real value.
+*/
+func (p *MinorPlanet) HasAbsoluteMagnitude() bool {
+	return p.missing&FieldAbsoluteMagnitude == 0
+}
+
+/*
+HasSlope reports whether the record had a slope parameter. The MPC assumes
+photometry.DefaultSlope when it is blank.
+*/
+func (p *MinorPlanet) HasSlope() bool {
+	return p.missing&FieldSlope == 0
+}
+
+/*
+HasNumberOfObservations reports whether the record had a number of
+observations.
+*/
+func (p *MinorPlanet) HasNumberOfObservations() bool {
+	return p.missing&FieldNumberOfObservations == 0
+}
+
+/*
+HasNumberOfOppositions reports whether the record had a number of
+oppositions.
+*/
+func (p *MinorPlanet) HasNumberOfOppositions() bool {
+	return p.missing&FieldNumberOfOppositions == 0
+}
+
+/*
+HasRMSResidual reports whether the record had an RMS residual. Averages of the
+residual should skip records where this is false rather than count them as a
+perfect fit.
+*/
+func (p *MinorPlanet) HasRMSResidual() bool {
+	return p.missing&FieldRMSResidual == 0
 }
 
 /*
@@ -82,58 +177,190 @@ This takes a path as a string to the file and returns the reader structure.
 If there is a problem opening the file it will return nil for the reader and an
 error indicating what went wrong.
 
-This will automatically detect if the file extension suggests a gziped version
-of the file and open it correctly.
+This will automatically detect if the content of the file is compressed and
+open it correctly, regardless of the file extension.
+See RegisterDecompressor for the supported formats.
 */
 func NewMpcReader(filePath string) (*MpcReader, error) {
-	var reader MpcReader
-	var err error
-	reader.f, err = os.Open(filePath)
+	src, err := openLineSource(filePath)
 	if err != nil {
 		return nil, err
 	}
+	return &MpcReader{src: src, kind: SourceKindFromName(filePath)}, nil
+}
 
-	if strings.HasSuffix(filePath, ".gz") {
-		reader.g, err = gzip.NewReader(reader.f)
-		if err != nil {
-			return nil, err
-		}
+/*
+NewMpcReaderFromReader creates a new minor planet center reader that reads
+records from any io.Reader, for example os.Stdin, a bytes.Buffer or the body of
+an http.Response.
 
-		reader.s = bufio.NewScanner(reader.g)
-	} else {
-		reader.g = nil
-		reader.s = bufio.NewScanner(reader.f)
-	}
+Compressed data is detected in the same way as NewMpcReader. Closing the
+returned reader will not close the provided io.Reader, that remains the
+responsibility of the caller.
+*/
+func NewMpcReaderFromReader(in io.Reader) (*MpcReader, error) {
+	return newMpcReader(in, nil)
+}
 
-	return &reader, nil
+/*
+NewMpcReaderFromReadCloser creates a new minor planet center reader that takes
+ownership of the provided io.ReadCloser. Calling Close() on the returned reader
+will also close the io.ReadCloser.
+*/
+func NewMpcReaderFromReadCloser(in io.ReadCloser) (*MpcReader, error) {
+	return newMpcReader(in, in)
+}
 
+func newMpcReader(in io.Reader, closer io.Closer) (*MpcReader, error) {
+	src, err := newLineSource(in, closer)
+	if err != nil {
+		return nil, err
+	}
+	return &MpcReader{src: src}, nil
 }
 
 /*
 MpcReader is a simple data structure to keep track of readers and scanners for
-reading the requested file. Should be constructed using NewMpcReader(string) and
-closed using Close() before disposal.
+reading the requested file. Should be constructed using NewMpcReader(string),
+NewMpcReaderFromReader(io.Reader) or NewMpcReaderFromReadCloser(io.ReadCloser)
+and closed using Close() before disposal.
 */
 type MpcReader struct {
-	f *os.File
-	g *gzip.Reader
-	s *bufio.Scanner
+	src  *lineSource
+	kind SourceKind
+
+	mode       ErrorMode
+	stats      ReaderStats
+	rejections []*ParseError
+}
+
+/*
+ErrorMode controls what ReadEntry does when it finds a record it can not parse.
+*/
+type ErrorMode int
+
+const (
+	// ErrorModeStrict returns the *ParseError from ReadEntry. This is the default.
+	ErrorModeStrict ErrorMode = iota
+	// ErrorModeSkip silently moves on to the next record.
+	ErrorModeSkip
+	// ErrorModeCollect moves on to the next record but keeps the error so it
+	// can be retrieved with Rejections().
+	ErrorModeCollect
+)
+
+/*
+ReaderStats holds counters about what a reader has seen so far.
+
+Read is the number of records successfully returned by ReadEntry.
+NonRecordLines is the number of lines that were not records at all, such as
+the header and blank separator lines. Rejected is the number of records that
+could not be parsed, whatever the error mode, so in ErrorModeSkip it is the
+number of bad records that were skipped.
+*/
+type ReaderStats struct {
+	Read           int64
+	NonRecordLines int64
+	Rejected       int64
+}
+
+/*
+SetErrorMode changes how the reader handles malformed records. This should be
+called before the first call to ReadEntry.
+*/
+func (reader *MpcReader) SetErrorMode(mode ErrorMode) {
+	reader.mode = mode
+}
+
+/*
+SourceKind returns which MPC file is being read. NewMpcReader works this out
+from the file name. Readers created from an io.Reader start as SourceUnknown
+and change to SourceMPCORB if the MPCORB header is seen, otherwise the caller
+can set it with SetSourceKind.
+*/
+func (reader *MpcReader) SourceKind() SourceKind {
+	return reader.kind
+}
+
+/*
+SetSourceKind records which MPC file is being read, for when the reader can't
+work it out itself.
+*/
+func (reader *MpcReader) SetSourceKind(kind SourceKind) {
+	reader.kind = kind
+}
+
+/*
+Stats returns the counters for the records read so far.
+*/
+func (reader *MpcReader) Stats() ReaderStats {
+	return reader.stats
+}
+
+/*
+Rejections returns the errors for every record rejected so far when the reader
+is in ErrorModeCollect. The raw line is available in the Record field of each
+error. In other modes this will always be empty.
+*/
+func (reader *MpcReader) Rejections() []*ParseError {
+	return reader.rejections
 }
 
 /*
 ReadEntry returns the next minor planet from the file or error if there is a problem
 reading the record.
 
+If the record could not be converted the error will be a *ParseError giving the
+line number and field that caused the problem. See SetErrorMode to skip or
+collect these instead.
+
 Note: this will return an io.EOF when the end of the file is reached.
 */
 func (reader *MpcReader) ReadEntry() (*MinorPlanet, error) {
-	buffer, err := reader.findLine()
-	if err != nil {
-		return nil, err
+	for {
+		buffer, err := reader.findLine()
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := convertToMinorPlanet(buffer)
+		if err == nil {
+			reader.stats.Read = reader.stats.Read + 1
+			return result, nil
+		}
+
+		pe, ok := reader.src.locate(err).(*ParseError)
+		if !ok {
+			return nil, err
+		}
+		reader.stats.Rejected = reader.stats.Rejected + 1
+
+		switch reader.mode {
+		case ErrorModeSkip:
+		case ErrorModeCollect:
+			reader.rejections = append(reader.rejections, pe)
+		default:
+			return nil, err
+		}
 	}
+}
+
+/*
+ParseRecord converts a single 202 column MPCORB line into a MinorPlanet without
+needing an MpcReader. A trailing line ending is ignored.
 
-	result, err := convertToMinorPlanet(buffer)
-	return result, err
+Errors are returned as a *ParseError in the same way as ReadEntry, although the
+Line and Offset fields will be zero as there is no surrounding file.
+*/
+func ParseRecord(line string) (*MinorPlanet, error) {
+	return convertToMinorPlanet(strings.TrimRight(line, "\r\n"))
+}
+
+/*
+ParseRecordBytes is the same as ParseRecord but takes a byte slice.
+*/
+func ParseRecordBytes(line []byte) (*MinorPlanet, error) {
+	return ParseRecord(string(line))
 }
 
 /*
@@ -142,10 +369,7 @@ Close the reader down. This will clean up the open file handle.
 This should be defered just after NewMpcReader has been called
 */
 func (reader *MpcReader) Close() {
-	if reader.g != nil {
-		reader.g.Close()
-	}
-	reader.f.Close()
+	reader.src.close()
 }
 
 /*
@@ -234,11 +458,42 @@ positions 3 and 6, position 5 can be ignored.
 
 The third starts with a two character code and has a packed int on the end.
 These should be swapped around to build the final identifier.
+
+Numbers above 619,999 don't fit in the first scheme so are written as a tilde
+followed by four base 62 digits counting up from 620,000. Likewise provisional
+designations with a cycle count above 619 start with an underscore, see
+readExtendedProvisional.
+
+Anything that doesn't fit one of these layouts returns a *DesignationError
+rather than guessing.
 */
-func readPackedIdentifier(buffer string) string {
+func readPackedIdentifier(buffer string) (string, error) {
+	if len(buffer) == 0 {
+		return "", ErrFieldWidth
+	}
+	if buffer[0] == '~' {
+		n, err := readTildeNumber(readString(buffer))
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(n, 10), nil
+	}
+	if buffer[0] == '_' {
+		return readExtendedProvisional(readString(buffer))
+	}
 	if onlyNumbers(buffer[1:]) {
-		return strconv.FormatInt(readPackedInt(buffer), 10)
-	} else if buffer[2] >= '0' && buffer[2] <= '9' {
+		if _, ok := base62Value(buffer[0]); !ok {
+			return "", designationError(buffer, "unrecognised packed number")
+		}
+		return strconv.FormatInt(readPackedInt(buffer), 10), nil
+	}
+	if len(buffer) < 7 {
+		return "", ErrFieldWidth
+	}
+	if buffer[2] >= '0' && buffer[2] <= '9' {
+		if !isUpper(buffer[3]) || !isPackedDigit(buffer[4]) || !isDigits(buffer[5:6]) || !isUpper(buffer[6]) {
+			return "", designationError(buffer, "unrecognised provisional designation")
+		}
 		var output bytes.Buffer
 		output.WriteString(strconv.FormatInt(readPackedInt(buffer[0:3]), 10))
 		output.WriteRune(' ')
@@ -248,27 +503,96 @@ func readPackedIdentifier(buffer string) string {
 		if number > 0 {
 			output.WriteString(strconv.FormatInt(number, 10))
 		}
-		return output.String()
-	} else {
-		var output bytes.Buffer
-		output.WriteString(strconv.FormatInt(readPackedInt(buffer[3:7]), 10))
-		output.WriteRune(' ')
-		output.WriteByte(buffer[0])
-		output.WriteRune('-')
-		output.WriteByte(buffer[1])
-		return output.String()
+		return output.String(), nil
+	}
+	switch buffer[0:3] {
+	case "PLS", "T1S", "T2S", "T3S":
+	default:
+		return "", designationError(buffer, "unrecognised survey designation")
+	}
+	var output bytes.Buffer
+	output.WriteString(strconv.FormatInt(readPackedInt(buffer[3:7]), 10))
+	output.WriteRune(' ')
+	output.WriteByte(buffer[0])
+	output.WriteRune('-')
+	output.WriteByte(buffer[1])
+	return output.String(), nil
+}
+
+/*
+Decodes the extended "~xxxx" numbering scheme.
+*/
+func readTildeNumber(buffer string) (int64, error) {
+	if len(buffer) != 5 || buffer[0] != '~' {
+		return 0, ErrFieldWidth
+	}
+	var result int64
+	for i := 1; i < len(buffer); i = i + 1 {
+		v, ok := base62Value(buffer[i])
+		if !ok {
+			return 0, designationError(buffer, "invalid character in extended number")
+		}
+		result = result*62 + v
+	}
+	return tildeOffset + result, nil
+}
+
+/*
+Decodes the extended provisional designations used when the cycle count is
+above 619, for example "_OA004S" is "2024 AB631".
+
+The second character is the year since 2000 as a single packed digit, the
+third is the half month letter. The last four are a base 62 number counting
+the designations in that half month from "AA620", with 25 per cycle.
+*/
+func readExtendedProvisional(buffer string) (string, error) {
+	if len(buffer) != 7 || buffer[0] != '_' {
+		return "", ErrFieldWidth
+	}
+	year, ok := base62Value(buffer[1])
+	if !ok || !isHalfMonth(buffer[2]) {
+		return "", designationError(buffer, "unrecognised extended provisional designation")
+	}
+	var count int64
+	for i := 3; i < len(buffer); i = i + 1 {
+		v, ok := base62Value(buffer[i])
+		if !ok {
+			return "", designationError(buffer, "unrecognised extended provisional designation")
+		}
+		count = count*62 + v
+	}
+	cycle := count/int64(len(cycleLetters)) + extendedCycleStart
+	letter := cycleLetters[count%int64(len(cycleLetters))]
+	return fmt.Sprintf("%d %c%c%d", 2000+year, buffer[2], letter, cycle), nil
+}
+
+/*
+The value of a single 0-9A-Za-z digit
+*/
+func base62Value(b byte) (int64, bool) {
+	switch {
+	case b >= '0' && b <= '9':
+		return int64(b - '0'), true
+	case b >= 'A' && b <= 'Z':
+		return int64(b-'A') + 10, true
+	case b >= 'a' && b <= 'z':
+		return int64(b-'a') + 36, true
 	}
+	return 0, false
 }
 
 /*
 Packed time fields are simply three packed int representing year, month and day
 */
-func readPackedTime(buffer string) time.Time {
+func readPackedTime(buffer string) (time.Time, error) {
 	tb := readString(buffer)
+	if len(tb) != 5 {
+		return time.Time{}, ErrFieldWidth
+	}
 	year := int(readPackedInt(tb[0:3]))
 	month := int(readPackedInt(tb[3:4]))
 	day := int(readPackedInt(tb[4:5]))
-	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC), nil
 }
 
 /*
@@ -302,120 +626,382 @@ func onlyNumbers(buffer string) bool {
 
 /*
 Read a line from the file.
-It will keep reading more lines until it finds one that is 203 characters long
-(The length of a data record).
+It will keep reading more lines until it finds one that is 202 characters long
+(The length of a data record), or one that is once trailing whitespace is
+removed, see trimmedRecord.
 
 If it gets to the end of the file it will return io.EOF for error
 */
 func (reader *MpcReader) findLine() (string, error) {
-	var result string
-	var err error
-
-	for len(result) != 202 {
-		if reader.s.Scan() {
-			result = reader.s.Text()
-		} else {
-			err = reader.s.Err()
-			if err != nil {
-				return "", err
-			}
-			return "", io.EOF
+	for {
+		result, err := reader.src.next()
+		if err != nil {
+			return "", err
+		}
+		if len(result) == mpcorbRecordLength {
+			return result, nil
+		}
+		if trimmed, ok := trimmedRecord(result); ok {
+			return trimmed, nil
 		}
+		if reader.kind == SourceUnknown && strings.Contains(result, mpcorbBanner) {
+			reader.kind = SourceMPCORB
+		}
+		reader.stats.NonRecordLines = reader.stats.NonRecordLines + 1
+	}
+}
+
+const mpcorbRecordLength = 202
+
+/*
+column describes where a field lives in a 202 character record. start and end
+are zero based and used directly to slice the record.
+*/
+type column struct {
+	field string
+	start int
+	end   int
+}
+
+var (
+	colID                           = column{"ID", 0, 7}
+	colAbsoluteMagnitude            = column{"AbsoluteMagnitude", 8, 13}
+	colSlope                        = column{"Slope", 14, 19}
+	colEpoch                        = column{"Epoch", 20, 25}
+	colMeanAnomalyEpoch             = column{"MeanAnomalyEpoch", 26, 35}
+	colArgumentOfPerihelion         = column{"ArgumentOfPerihelion", 37, 47}
+	colLongitudeOfTheAscendingNode  = column{"LongitudeOfTheAscendingNode", 48, 57}
+	colInclinationToTheEcliptic     = column{"InclinationToTheEcliptic", 59, 68}
+	colOrbitalEccentricity          = column{"OrbitalEccentricity", 70, 79}
+	colMeanDailyMotion              = column{"MeanDailyMotion", 80, 91}
+	colSemimajorAxis                = column{"SemimajorAxis", 92, 103}
+	colUncertaintyParameter         = column{"UncertaintyParameter", 105, 106}
+	colReference                    = column{"Reference", 107, 116}
+	colNumberOfObservations         = column{"NumberOfObservations", 117, 122}
+	colNumberOfOppositions          = column{"NumberOfOppositions", 123, 126}
+	colYearOfFirstObservation       = column{"YearOfFirstObservation", 127, 131}
+	colYearOfLastObservation        = column{"YearOfLastObservation", 132, 136}
+	colArcLength                    = column{"ArcLength", 127, 136}
+	colRMSResidual                  = column{"RMSResidual", 137, 141}
+	colCoarseIndicatorOfPerturbers  = column{"CoarseIndicatorOfPerturbers", 142, 145}
+	colPreciseIndicatorOfPerturbers = column{"PreciseIndicatorOfPerturbers", 146, 149}
+	colComputerName                 = column{"ComputerName", 150, 160}
+	colHexDigitFlags                = column{"HexDigitFlags", 161, 165}
+	colReadableDesignation          = column{"ReadableDesignation", 166, 194}
+	colDateOfLastObservation        = column{"DateOfLastObservation", 194, 202}
+)
+
+/*
+Cut this column out of the buffer. Returns ErrShortRecord wrapped in a
+ParseError if the buffer doesn't reach the end of the column.
+*/
+func (c column) slice(buffer string) (string, error) {
+	if len(buffer) < c.end {
+		return "", c.error(buffer, ErrShortRecord)
+	}
+	return buffer[c.start:c.end], nil
+}
+
+/*
+Wrap an error from reading this column in a ParseError
+*/
+func (c column) error(buffer string, err error) error {
+	return &ParseError{
+		Field:       c.field,
+		StartColumn: c.start + 1,
+		EndColumn:   c.end,
+		Record:      buffer,
+		Err:         err,
+	}
+}
+
+func (c column) readString(buffer string) (string, error) {
+	s, err := c.slice(buffer)
+	if err != nil {
+		return "", err
+	}
+	return readString(s), nil
+}
+
+func (c column) readFloat(buffer string) (float64, error) {
+	s, err := c.slice(buffer)
+	if err != nil {
+		return 0, err
+	}
+	v, err := readFloat(s)
+	if err != nil {
+		return 0, c.error(buffer, err)
+	}
+	return v, nil
+}
+
+/*
+Reads a float from a column that is allowed to be blank. The bool is false
+when the column was blank, anything else that doesn't parse is an error.
+*/
+func (c column) readBlankableFloat(buffer string) (float64, bool, error) {
+	s, err := c.slice(buffer)
+	if err != nil {
+		return 0, false, err
+	}
+	if strings.TrimSpace(s) == "" {
+		return 0, false, nil
+	}
+	v, err := readFloat(s)
+	if err != nil {
+		return 0, false, c.error(buffer, err)
+	}
+	return v, true, nil
+}
+
+func (c column) readInt(buffer string) (int64, error) {
+	s, err := c.slice(buffer)
+	if err != nil {
+		return 0, err
+	}
+	v, err := readInt(s)
+	if err != nil {
+		return 0, c.error(buffer, err)
+	}
+	return v, nil
+}
+
+/*
+The integer version of readBlankableFloat
+*/
+func (c column) readBlankableInt(buffer string) (int64, bool, error) {
+	s, err := c.slice(buffer)
+	if err != nil {
+		return 0, false, err
+	}
+	if strings.TrimSpace(s) == "" {
+		return 0, false, nil
+	}
+	v, err := readInt(s)
+	if err != nil {
+		return 0, false, c.error(buffer, err)
+	}
+	return v, true, nil
+}
+
+func (c column) readHexInt(buffer string) (int64, error) {
+	s, err := c.slice(buffer)
+	if err != nil {
+		return 0, err
+	}
+	v, err := readHexInt(s)
+	if err != nil {
+		return 0, c.error(buffer, err)
+	}
+	return v, nil
+}
+
+func (c column) readTime(buffer string) (time.Time, error) {
+	s, err := c.slice(buffer)
+	if err != nil {
+		return time.Time{}, err
 	}
-	return result, nil
+	v, err := readTime(s)
+	if err != nil {
+		return time.Time{}, c.error(buffer, err)
+	}
+	return v, nil
+}
+
+func (c column) readPackedTime(buffer string) (time.Time, error) {
+	s, err := c.slice(buffer)
+	if err != nil {
+		return time.Time{}, err
+	}
+	v, err := readPackedTime(s)
+	if err != nil {
+		return time.Time{}, c.error(buffer, err)
+	}
+	return v, nil
+}
+
+func (c column) readDesignation(buffer string) (Designation, error) {
+	s, err := c.slice(buffer)
+	if err != nil {
+		return Designation{}, err
+	}
+	v, err := ParsePackedDesignation(s)
+	if err != nil {
+		return Designation{}, c.error(buffer, err)
+	}
+	return v, nil
+}
+
+func (c column) readArcLength(buffer string) (int64, error) {
+	s, err := c.slice(buffer)
+	if err != nil {
+		return 0, err
+	}
+	v, err := readArcLength(s)
+	if err != nil {
+		return 0, c.error(buffer, err)
+	}
+	return v, nil
 }
 
 /*
 Convert a byte buffer into a minor planet. This takes apart the buffer and
 populates. The MinorPlanet struct
+
+Any error returned will be a *ParseError identifying the field that failed.
+This will never panic, a buffer that is too short for a field will return a
+ParseError wrapping ErrShortRecord.
 */
 func convertToMinorPlanet(buffer string) (*MinorPlanet, error) {
 	var r MinorPlanet
 	var err error
 
-	r.ID = readPackedIdentifier(buffer[0:7])
+	r.Designation, err = colID.readDesignation(buffer)
+	if err != nil {
+		return nil, err
+	}
+	r.ID = r.Designation.String()
 
 	// the following two columns are alowed to be blank
-	r.AbsoluteMagnitude, _ = readFloat(buffer[8:13])
-	r.Slope, _ = readFloat(buffer[14:19])
+	var ok bool
+	r.AbsoluteMagnitude, ok, err = colAbsoluteMagnitude.readBlankableFloat(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		r.missing |= FieldAbsoluteMagnitude
+	}
 
-	r.Epoch = readPackedTime(buffer[20:25])
+	r.Slope, ok, err = colSlope.readBlankableFloat(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		r.missing |= FieldSlope
+	}
 
-	r.MeanAnomalyEpoch, err = readFloat(buffer[26:35])
+	r.Epoch, err = colEpoch.readPackedTime(buffer)
 	if err != nil {
 		return nil, err
 	}
 
-	r.ArgumentOfPerihelion, err = readFloat(buffer[37:47])
+	r.MeanAnomalyEpoch, err = colMeanAnomalyEpoch.readFloat(buffer)
 	if err != nil {
 		return nil, err
 	}
 
-	r.LongitudeOfTheAscendingNode, err = readFloat(buffer[48:57])
+	r.ArgumentOfPerihelion, err = colArgumentOfPerihelion.readFloat(buffer)
 	if err != nil {
 		return nil, err
 	}
 
-	r.InclinationToTheEcliptic, err = readFloat(buffer[59:68])
+	r.LongitudeOfTheAscendingNode, err = colLongitudeOfTheAscendingNode.readFloat(buffer)
 	if err != nil {
 		return nil, err
 	}
 
-	r.OrbitalEccentricity, err = readFloat(buffer[70:79])
+	r.InclinationToTheEcliptic, err = colInclinationToTheEcliptic.readFloat(buffer)
 	if err != nil {
 		return nil, err
 	}
 
-	r.MeanDailyMotion, err = readFloat(buffer[80:91])
+	r.OrbitalEccentricity, err = colOrbitalEccentricity.readFloat(buffer)
 	if err != nil {
 		return nil, err
 	}
 
-	r.SemimajorAxis, err = readFloat(buffer[92:103])
+	r.MeanDailyMotion, err = colMeanDailyMotion.readFloat(buffer)
 	if err != nil {
 		return nil, err
 	}
 
-	r.UncertaintyParameter = readString(buffer[105:106])
-	r.Reference = readString(buffer[107:116])
-	r.NumberOfObservations, _ = readInt(buffer[117:122])
-	r.NumberOfOppositions, _ = readInt(buffer[123:126])
+	r.SemimajorAxis, err = colSemimajorAxis.readFloat(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	r.UncertaintyParameter, err = colUncertaintyParameter.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Reference, err = colReference.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	r.NumberOfObservations, ok, err = colNumberOfObservations.readBlankableInt(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		r.missing |= FieldNumberOfObservations
+	}
+
+	r.NumberOfOppositions, ok, err = colNumberOfOppositions.readBlankableInt(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		r.missing |= FieldNumberOfOppositions
+	}
 
 	// The next column has different values depending on the NumberOfOppositions
 	// When there has been more than one there are two years showing the first and last observations
 	// When there is less than or equal one then there is the amount of orbit we have seen.
 	if r.NumberOfOppositions > 1 {
-		r.YearOfFirstObservation, err = readInt(buffer[127:131])
+		r.YearOfFirstObservation, err = colYearOfFirstObservation.readInt(buffer)
 		if err != nil {
 			return nil, err
 		}
 
-		r.YearOfLastObservation, err = readInt(buffer[132:136])
+		r.YearOfLastObservation, err = colYearOfLastObservation.readInt(buffer)
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		r.ArcLength, err = readArcLength(buffer[127:136])
+		r.ArcLength, err = colArcLength.readArcLength(buffer)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	// This column is optional. Some times it is blank
-	r.RMSResidual, _ = readFloat(buffer[137:141])
+	r.RMSResidual, ok, err = colRMSResidual.readBlankableFloat(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		r.missing |= FieldRMSResidual
+	}
 
-	r.CoarseIndicatorOfPerturbers = readString(buffer[142:145])
-	r.PreciseIndicatorOfPerturbers = readString(buffer[146:149])
-	r.ComputerName = readString(buffer[150:160])
+	r.CoarseIndicatorOfPerturbers, err = colCoarseIndicatorOfPerturbers.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
 
-	r.HexDigitFlags, err = readHexInt(buffer[161:165])
+	r.PreciseIndicatorOfPerturbers, err = colPreciseIndicatorOfPerturbers.readString(buffer)
 	if err != nil {
 		return nil, err
 	}
 
-	r.ReadableDesignation = readString(buffer[166:194])
+	r.ComputerName, err = colComputerName.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	r.HexDigitFlags, err = colHexDigitFlags.readHexInt(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	r.ReadableDesignation, err = colReadableDesignation.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+	r.Number, r.Name, r.ProvisionalDesignation = splitReadableDesignation(r.ReadableDesignation)
 
-	r.DateOfLastObservation, err = readTime(buffer[194:202])
+	r.DateOfLastObservation, err = colDateOfLastObservation.readTime(buffer)
 	if err != nil {
 		return nil, err
 	}
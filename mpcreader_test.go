@@ -1,7 +1,10 @@
 package gompcreader
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -201,7 +204,20 @@ func TestReadPackedIdentifier(t *testing.T) {
 	stringTest(t,
 		"readPackedIdentifier",
 		packedIdentifierTests,
-		readPackedIdentifier)
+		func(c string) string {
+			var r, e = readPackedIdentifier(c)
+			assert.Nil(t, e)
+			return r
+		})
+}
+
+var packedIdentifierErrorTests = []string{"", "J95X", "PLS20", "K13B4A"}
+
+func TestReadPackedIdentifierErrors(t *testing.T) {
+	for _, tt := range packedIdentifierErrorTests {
+		_, e := readPackedIdentifier(tt)
+		assert.Equal(t, ErrFieldWidth, e, "readPackedIdentifier(%q)", tt)
+	}
 }
 
 var arcLengthTests = []intTestCase{
@@ -242,7 +258,8 @@ var packedDateTests = []struct {
 
 func TestReadPackedDate(t *testing.T) {
 	for _, tt := range packedDateTests {
-		var result = readPackedTime(tt.in)
+		var result, err = readPackedTime(tt.in)
+		assert.Nil(t, err)
 		if !tt.out.Equal(result) {
 			t.Errorf(
 				"readPackedTime(%s) = %s expected %s",
@@ -366,13 +383,13 @@ func TestConvertT3S5154(t *testing.T) {
 
 var convertErrorsTests = []stringTestCase{
 	{"ajghfjhsdfjkhgjfkghjfhgjfhgjsfhgjhfjghfdjkh",
-		"strconv.ParseFloat: parsing \"gjsfhgjhf\": invalid syntax"},
+		"ID (columns 1-7): invalid designation \"ajghfjh\": unrecognised packed format"},
 	{"00001    3.34  0.12 K13B4  10.55761  sjhagjkfhgjkshfgjl",
-		"strconv.ParseFloat: parsing \"sjhagjkfhg\": invalid syntax"},
+		"ArgumentOfPerihelion (columns 38-47): strconv.ParseFloat: parsing \"sjhagjkfhg\": invalid syntax"},
 	{"00001    3.34  0.12 K13B4  dshgsh  sjhagjkfhgjkshfgjl",
-		"strconv.ParseFloat: parsing \"dshgsh\": invalid syntax"},
+		"MeanAnomalyEpoch (columns 27-35): strconv.ParseFloat: parsing \"dshgsh\": invalid syntax"},
 	{"00001    3.34  0.12 K13B4  10.55761   72.29213  jajhkhs  hfdsjkgjkh",
-		"strconv.ParseFloat: parsing \"jajhkhs\": invalid syntax"},
+		"LongitudeOfTheAscendingNode (columns 49-57): strconv.ParseFloat: parsing \"jajhkhs\": invalid syntax"},
 }
 
 func TestConverErrors(t *testing.T) {
@@ -384,3 +401,150 @@ func TestConverErrors(t *testing.T) {
 			return e.Error()
 		})
 }
+
+const ceresLine = "00001    3.34  0.12 K13B4  10.55761   72.29213   80.32762   10.59398  0.0757973  0.21415869   2.7668073  0 MPO286777  6502 105 1802-2014 0.82 M-v 30h MPCLINUX   0000      (1) Ceres              20140307"
+const t3s5154Line = "T3S5154 17.1   0.15 J77AO  17.78418  247.82110  104.38071    9.61380  0.2757131  0.18128053   3.0919701    MPC 12559     8   1    6 days              Bardwell   2000          5154 T-3           19771017"
+
+var readerInput = "MINOR PLANET CENTER ORBIT DATABASE (MPCORB)\n\n" +
+	"Des'n     H     G   Epoch     M        Peri.      Node       Incl.       e            n           a        Reference #Obs #Opp    Arc    rms  Perts   Computer\n" +
+	"----------------------------------------------------------------------------------------------------------------------------------------------------------------\n" +
+	ceresLine + "\n\n" + t3s5154Line + "\n"
+
+func TestNewMpcReaderFromReader(t *testing.T) {
+	reader, err := NewMpcReaderFromReader(strings.NewReader(readerInput))
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	result, err := reader.ReadEntry()
+	assert.Nil(t, err)
+	assert.Equal(t, "1", result.ID)
+
+	result, err = reader.ReadEntry()
+	assert.Nil(t, err)
+	assert.Equal(t, "5154 T-3", result.ID)
+
+	_, err = reader.ReadEntry()
+	assert.Equal(t, io.EOF, err)
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestNewMpcReaderFromReadCloser(t *testing.T) {
+	in := &closeRecorder{Reader: strings.NewReader(readerInput)}
+	reader, err := NewMpcReaderFromReadCloser(in)
+	assert.Nil(t, err)
+
+	result, err := reader.ReadEntry()
+	assert.Nil(t, err)
+	assert.Equal(t, "1", result.ID)
+
+	reader.Close()
+	assert.True(t, in.closed, "Close() should close the wrapped io.ReadCloser")
+}
+
+func TestConvertShortRecord(t *testing.T) {
+	for i := 0; i < len(ceresLine); i++ {
+		_, err := convertToMinorPlanet(ceresLine[:i])
+		assert.NotNil(t, err, "convertToMinorPlanet accepted a %d character record", i)
+	}
+
+	_, err := convertToMinorPlanet(ceresLine[:150])
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "ComputerName", pe.Field)
+	assert.Equal(t, ErrShortRecord, pe.Err)
+}
+
+var packedDateErrorTests = []string{"", "J23", "   ", "J2319A"}
+
+func TestReadPackedDateErrors(t *testing.T) {
+	for _, tt := range packedDateErrorTests {
+		_, e := readPackedTime(tt)
+		assert.Equal(t, ErrFieldWidth, e, "readPackedTime(%q)", tt)
+	}
+}
+
+func FuzzConvertToMinorPlanet(f *testing.F) {
+	f.Add(ceresLine)
+	f.Add(t3s5154Line)
+	f.Add("")
+	f.Add("00001    3.34  0.12 K13B4  10.55761")
+	f.Fuzz(func(t *testing.T, line string) {
+		r, err := convertToMinorPlanet(line)
+		if err == nil && r == nil {
+			t.Fatalf("convertToMinorPlanet(%q) returned neither a result nor an error", line)
+		}
+		if err != nil {
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("convertToMinorPlanet(%q) returned %T, expected *ParseError", line, err)
+			}
+		}
+	})
+}
+
+func FuzzReadPackedIdentifier(f *testing.F) {
+	for _, tt := range packedIdentifierTests {
+		f.Add(tt.in)
+	}
+	f.Add("")
+	f.Add("J9")
+	f.Fuzz(func(t *testing.T, in string) {
+		readPackedIdentifier(in)
+		readPackedTime(in)
+	})
+}
+
+func TestParseRecord(t *testing.T) {
+	result, err := ParseRecord(ceresLine + "\r\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", result.ID)
+	assert.Equal(t, "(1) Ceres", result.ReadableDesignation)
+
+	result, err = ParseRecordBytes([]byte(t3s5154Line))
+	assert.Nil(t, err)
+	assert.Equal(t, "5154 T-3", result.ID)
+
+	_, err = ParseRecord(ceresLine[:100])
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, int64(0), pe.Line)
+	assert.Equal(t, ErrShortRecord, pe.Err)
+}
+
+func TestMissingOptionalFields(t *testing.T) {
+	ceres, err := ParseRecord(ceresLine)
+	assert.Nil(t, err)
+	assert.Equal(t, OptionalFields(0), ceres.Missing())
+	assert.True(t, ceres.HasNumberOfObservations())
+	assert.True(t, ceres.HasNumberOfOppositions())
+	assert.True(t, ceres.HasRMSResidual())
+
+	// T3S5154 has no RMS residual in the file
+	survey, err := ParseRecord(t3s5154Line)
+	assert.Nil(t, err)
+	assert.Equal(t, FieldRMSResidual, survey.Missing())
+	assert.False(t, survey.HasRMSResidual())
+	assert.Equal(t, 0.0, survey.RMSResidual)
+
+	blank, err := ParseRecord(blankColumns(ceresLine, colNumberOfObservations, colRMSResidual))
+	assert.Nil(t, err)
+	assert.Equal(t, FieldNumberOfObservations|FieldRMSResidual, blank.Missing())
+	assert.False(t, blank.HasNumberOfObservations())
+	assert.True(t, blank.HasNumberOfOppositions())
+
+	// a column that isn't blank must parse
+	broken := ceresLine[:colNumberOfObservations.start] + " 65x2" + ceresLine[colNumberOfObservations.end:]
+	_, err = ParseRecord(broken)
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "NumberOfObservations", pe.Field)
+}
@@ -0,0 +1,701 @@
+package gompcreader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Observation is a single optical observation of a minor planet as found in the
+MPC observation files, such as NumObs.txt and UnnObs.txt, which use the 80
+column format.
+
+ID is the same as the ID of the matching MinorPlanet: the number if the
+object is numbered, otherwise the unpacked provisional designation. Objects
+that only have an observer assigned temporary designation use that instead.
+
+RightAscension and Declination are in degrees, J2000.0. Time is UTC.
+Magnitude is zero when no magnitude was reported, use HasMagnitude to tell
+that apart from a measured 0.0.
+
+Satellite is set for observations made from a spacecraft (Note2 "S") and
+Roving for observations from a roving observer (Note2 "V"). Both of these
+use a second line in the file which is read along with the first.
+
+Mode is the ADES observation mode, such as "CCD", which is worked out from
+Note2 for the 80 column format. Catalog, RMSRightAscension and RMSDeclination
+are only available from ADES, the RMS values are in arc seconds.
+*/
+type Observation struct {
+	Number                 int64
+	ProvisionalDesignation string
+	TemporaryDesignation   string
+	ID                     string
+	Discovery              bool
+	Note1                  string
+	Note2                  string
+	Time                   time.Time
+	RightAscension         float64
+	Declination            float64
+	Magnitude              float64
+	Band                   string
+	ObservatoryCode        string
+	Satellite              *SatellitePosition
+	Roving                 *RovingPosition
+	Mode                   string
+	Catalog                string
+	RMSRightAscension      float64
+	RMSDeclination         float64
+
+	// missing records which of the optional fields were blank
+	missing OptionalFields
+}
+
+/*
+Missing returns the set of optional fields that were blank in the observation.
+Only FieldMagnitude applies to observations.
+*/
+func (o *Observation) Missing() OptionalFields {
+	return o.missing
+}
+
+/*
+SetMissing replaces the set of optional fields that have no value. The
+writers leave these out whatever the value of the field.
+*/
+func (o *Observation) SetMissing(fields OptionalFields) {
+	o.missing = fields
+}
+
+/*
+HasMagnitude reports whether a magnitude was reported with the observation.
+*/
+func (o *Observation) HasMagnitude() bool {
+	return o.missing&FieldMagnitude == 0
+}
+
+/*
+SatellitePosition is the geocentric position of a spacecraft observer. Units
+is 1 when X, Y and Z are in km and 2 when they are in AU.
+*/
+type SatellitePosition struct {
+	Units int
+	X     float64
+	Y     float64
+	Z     float64
+}
+
+/*
+RovingPosition is the location of an observer without an observatory code.
+Longitude is east of Greenwich and Latitude is geodetic, both in degrees.
+Altitude is in metres.
+*/
+type RovingPosition struct {
+	Longitude float64
+	Latitude  float64
+	Altitude  float64
+}
+
+/*
+ObservationReader reads records from the MPC 80 column observation files. It
+works in the same way as MpcReader, construct it with
+NewObservationReader(string) and call ReadEntry() until it returns io.EOF,
+then Close() it.
+*/
+type ObservationReader struct {
+	src *lineSource
+}
+
+/*
+NewObservationReader opens the observation file at filePath. Compression is
+detected in the same way as NewMpcReader.
+*/
+func NewObservationReader(filePath string) (*ObservationReader, error) {
+	src, err := openLineSource(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return &ObservationReader{src: src}, nil
+}
+
+/*
+NewObservationReaderFromReader creates an observation reader that reads from
+in. Closing the returned reader will not close in.
+*/
+func NewObservationReaderFromReader(in io.Reader) (*ObservationReader, error) {
+	src, err := newLineSource(in, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ObservationReader{src: src}, nil
+}
+
+/*
+NewObservationReaderFromReadCloser creates an observation reader that takes
+ownership of in and closes it when the reader is closed.
+*/
+func NewObservationReaderFromReadCloser(in io.ReadCloser) (*ObservationReader, error) {
+	src, err := newLineSource(in, in)
+	if err != nil {
+		return nil, err
+	}
+	return &ObservationReader{src: src}, nil
+}
+
+/*
+ReadEntry returns the next observation from the file. Blank lines are
+skipped, as are radar observations (Note2 "R" and "r") as they don't carry a
+position on the sky.
+
+If the record could not be converted the error will be a *ParseError. This
+will return io.EOF when the end of the file is reached.
+*/
+func (reader *ObservationReader) ReadEntry() (*Observation, error) {
+	for {
+		line, err := reader.src.next()
+		if err != nil {
+			return nil, err
+		}
+		line = padObservationLine(line)
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		note2 := line[colObsNote2.start]
+		if note2 == 'R' || note2 == 'r' {
+			continue
+		}
+
+		result, err := ParseObservation(line)
+		if err != nil {
+			return nil, reader.src.locate(err)
+		}
+
+		if note2 == 'S' || note2 == 'V' {
+			second, err := reader.src.next()
+			if err == io.EOF {
+				return nil, reader.src.locate(colObsNote2.error(line, errMissingSecondLine))
+			}
+			if err != nil {
+				return nil, err
+			}
+			if err := addSecondLine(result, padObservationLine(second)); err != nil {
+				return nil, reader.src.locate(err)
+			}
+		}
+		return result, nil
+	}
+}
+
+/*
+Close the reader down. This will clean up the open file handle.
+*/
+func (reader *ObservationReader) Close() {
+	reader.src.close()
+}
+
+const observationRecordLength = 80
+
+var errMissingSecondLine = errors.New("observation is missing its second line")
+
+var (
+	colObsNumber          = column{"Number", 0, 5}
+	colObsDesignation     = column{"ProvisionalDesignation", 5, 12}
+	colObsDiscovery       = column{"Discovery", 12, 13}
+	colObsNote1           = column{"Note1", 13, 14}
+	colObsNote2           = column{"Note2", 14, 15}
+	colObsYear            = column{"Time", 15, 19}
+	colObsMonth           = column{"Time", 20, 22}
+	colObsDay             = column{"Time", 23, 32}
+	colObsTime            = column{"Time", 15, 32}
+	colObsRightAscension  = column{"RightAscension", 32, 44}
+	colObsDeclination     = column{"Declination", 44, 56}
+	colObsMagnitude       = column{"Magnitude", 65, 70}
+	colObsBand            = column{"Band", 70, 71}
+	colObsObservatoryCode = column{"ObservatoryCode", 77, 80}
+
+	colObsSatelliteUnits = column{"Satellite.Units", 32, 33}
+	colObsSatelliteX     = column{"Satellite.X", 34, 46}
+	colObsSatelliteY     = column{"Satellite.Y", 46, 58}
+	colObsSatelliteZ     = column{"Satellite.Z", 58, 70}
+
+	colObsRovingLongitude = column{"Roving.Longitude", 34, 44}
+	colObsRovingLatitude  = column{"Roving.Latitude", 45, 55}
+	colObsRovingAltitude  = column{"Roving.Altitude", 56, 61}
+)
+
+/*
+Trailing spaces are often trimmed so pad lines back out to 80 characters
+*/
+func padObservationLine(line string) string {
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) < observationRecordLength {
+		line = line + strings.Repeat(" ", observationRecordLength-len(line))
+	}
+	return line
+}
+
+/*
+ParseObservation converts the first line of an 80 column observation. For
+satellite and roving observations the second line is not read, so Satellite
+and Roving will be nil. Use ObservationReader to get those as well.
+*/
+func ParseObservation(line string) (*Observation, error) {
+	buffer := padObservationLine(line)
+	var r Observation
+	var err error
+
+	number, err := colObsNumber.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if number != "" {
+		id, err := UnpackDesignation(number)
+		if err != nil {
+			return nil, colObsNumber.error(buffer, err)
+		}
+		r.Number, err = strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, colObsNumber.error(buffer, err)
+		}
+	}
+
+	designation, err := colObsDesignation.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if designation != "" {
+		unpacked, err := UnpackDesignation(designation)
+		if err == nil {
+			r.ProvisionalDesignation = unpacked
+		} else {
+			r.TemporaryDesignation = designation
+		}
+	}
+
+	if err := r.setID(); err != nil {
+		return nil, colObsDesignation.error(buffer, err)
+	}
+
+	discovery, err := colObsDiscovery.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+	r.Discovery = discovery == "*"
+
+	r.Note1, err = colObsNote1.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+	r.Note2, err = colObsNote2.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+	r.Mode = note2Mode(r.Note2)
+
+	r.Time, err = readObservationTime(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	ra, err := colObsRightAscension.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := readSexagesimal(ra)
+	if err != nil {
+		return nil, colObsRightAscension.error(buffer, err)
+	}
+	r.RightAscension = hours * 15
+
+	dec, err := colObsDeclination.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+	r.Declination, err = readSexagesimal(dec)
+	if err != nil {
+		return nil, colObsDeclination.error(buffer, err)
+	}
+
+	var ok bool
+	r.Magnitude, ok, err = colObsMagnitude.readBlankableFloat(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		r.missing |= FieldMagnitude
+	}
+	r.Band, err = colObsBand.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	r.ObservatoryCode, err = colObsObservatoryCode.readString(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+/*
+Fills in ID from the number or designations, in that order of preference.
+*/
+func (o *Observation) setID() error {
+	switch {
+	case o.Number > 0:
+		o.ID = strconv.FormatInt(o.Number, 10)
+	case o.ProvisionalDesignation != "":
+		o.ID = o.ProvisionalDesignation
+	case o.TemporaryDesignation != "":
+		o.ID = o.TemporaryDesignation
+	default:
+		return designationError("", "no designation given")
+	}
+	return nil
+}
+
+/*
+Reads the second line of a satellite or roving observer record into the
+observation made from the first line.
+*/
+func addSecondLine(r *Observation, buffer string) error {
+	note2 := buffer[colObsNote2.start : colObsNote2.start+1]
+	expected := strings.ToLower(r.Note2)
+	if note2 != expected {
+		return colObsNote2.error(buffer, fmt.Errorf("expected a second line of type %s", expected))
+	}
+
+	var err error
+	switch note2 {
+	case "s":
+		var s SatellitePosition
+		units, err := colObsSatelliteUnits.readInt(buffer)
+		if err != nil {
+			return err
+		}
+		s.Units = int(units)
+		if s.X, err = colObsSatelliteX.readSignedFloat(buffer); err != nil {
+			return err
+		}
+		if s.Y, err = colObsSatelliteY.readSignedFloat(buffer); err != nil {
+			return err
+		}
+		if s.Z, err = colObsSatelliteZ.readSignedFloat(buffer); err != nil {
+			return err
+		}
+		r.Satellite = &s
+	case "v":
+		var v RovingPosition
+		if v.Longitude, err = colObsRovingLongitude.readFloat(buffer); err != nil {
+			return err
+		}
+		if v.Latitude, err = colObsRovingLatitude.readSignedFloat(buffer); err != nil {
+			return err
+		}
+		if v.Altitude, err = colObsRovingAltitude.readFloat(buffer); err != nil {
+			return err
+		}
+		r.Roving = &v
+	}
+	return nil
+}
+
+/*
+The observation time is written "YYYY MM DD.ddddd"
+*/
+func readObservationTime(buffer string) (time.Time, error) {
+	year, err := colObsYear.readInt(buffer)
+	if err != nil {
+		return time.Time{}, err
+	}
+	month, err := colObsMonth.readInt(buffer)
+	if err != nil {
+		return time.Time{}, err
+	}
+	day, err := colObsDay.readFloat(buffer)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if month < 1 || month > 12 || day < 0 || day >= 32 {
+		return time.Time{}, colObsDay.error(buffer,
+			fmt.Errorf("invalid observation date %d-%d-%f", year, month, day))
+	}
+	return fractionalDate(int(year), time.Month(month), day), nil
+}
+
+/*
+Reads a space separated sexagesimal value such as "03 27 11.40" or
+"-23 11 05.12". Lower precision observations may leave off the seconds,
+"03 27.2".
+*/
+func readSexagesimal(buffer string) (float64, error) {
+	s := readString(buffer)
+	negative := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+	parts := strings.Fields(s)
+	if len(parts) < 1 || len(parts) > 3 {
+		return 0, fmt.Errorf("sexagesimal value %q should have one to three parts", buffer)
+	}
+	var result float64
+	var scale = 1.0
+	for _, p := range parts {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return 0, err
+		}
+		result = result + v/scale
+		scale = scale * 60
+	}
+	if negative {
+		result = -result
+	}
+	return result, nil
+}
+
+/*
+Signed values on the second lines are written with spaces between the sign and
+the number, "- 3548.6210"
+*/
+func (c column) readSignedFloat(buffer string) (float64, error) {
+	s, err := c.slice(buffer)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseFloat(strings.Replace(readString(s), " ", "", -1), 64)
+	if err != nil {
+		return 0, c.error(buffer, err)
+	}
+	return v, nil
+}
+
+/*
+Maps the 80 column Note2 observation type to an ADES mode. "A" only says the
+observation was converted from B1950, which doesn't tell us the mode, so it is
+left as unknown.
+*/
+var note2Modes = map[string]string{
+	"C": "CCD",
+	"c": "CCD",
+	"K": "CCD",
+	"S": "CCD",
+	"V": "CCD",
+	"B": "CMO",
+	"P": "PHO",
+	"e": "ENC",
+	"T": "MER",
+	"M": "MIC",
+	"n": "VID",
+	"E": "OCC",
+}
+
+func note2Mode(note2 string) string {
+	if mode, ok := note2Modes[note2]; ok {
+		return mode
+	}
+	return "UNK"
+}
+
+/*
+The inverse of note2Mode. Satellite and roving observations have their own
+types in the 80 column format.
+*/
+func modeNote2(o *Observation) string {
+	switch {
+	case o.Satellite != nil:
+		return "S"
+	case o.Roving != nil:
+		return "V"
+	}
+	switch o.Mode {
+	case "CCD":
+		return "C"
+	case "CMO":
+		return "B"
+	case "PHO":
+		return "P"
+	case "ENC":
+		return "e"
+	case "MER":
+		return "T"
+	case "MIC":
+		return "M"
+	case "VID":
+		return "n"
+	case "OCC":
+		return "E"
+	}
+	return ""
+}
+
+/*
+FormatObservation converts an observation into the 80 column format. This is
+the inverse of ParseObservation. Satellite and roving observations produce two
+lines separated by a new line character.
+
+If Note2 is blank it is worked out from Mode. RA is written to a thousandth of
+a second of time and Dec to a hundredth of an arc second.
+*/
+func FormatObservation(o *Observation) (string, error) {
+	line := []byte(strings.Repeat(" ", observationRecordLength))
+
+	number, designation, err := packObservationDesignation(o)
+	if err != nil {
+		return "", err
+	}
+
+	note2 := o.Note2
+	if note2 == "" {
+		note2 = modeNote2(o)
+	}
+	discovery := ""
+	if o.Discovery {
+		discovery = "*"
+	}
+	magnitude := ""
+	if o.HasMagnitude() {
+		magnitude = fmt.Sprintf("%5.1f", o.Magnitude)
+	}
+	date := formatObservationTime(o.Time)
+
+	fields := []formatField{
+		{colObsNumber, number},
+		{colObsDesignation, designation},
+		{colObsDiscovery, discovery},
+		{colObsNote1, o.Note1},
+		{colObsNote2, note2},
+		{colObsTime, date},
+		{colObsRightAscension, formatSexagesimal(o.RightAscension/15, 3, false)},
+		{colObsDeclination, formatSexagesimal(o.Declination, 2, true)},
+		{colObsMagnitude, magnitude},
+		{colObsBand, o.Band},
+		{colObsObservatoryCode, o.ObservatoryCode},
+	}
+	for _, f := range fields {
+		if err := f.c.put(line, f.value); err != nil {
+			return "", err
+		}
+	}
+
+	if o.Satellite == nil && o.Roving == nil {
+		return string(line), nil
+	}
+
+	second := []byte(strings.Repeat(" ", observationRecordLength))
+	fields = []formatField{
+		{colObsNumber, number},
+		{colObsDesignation, designation},
+		{colObsNote2, strings.ToLower(note2)},
+		{colObsTime, date},
+		{colObsObservatoryCode, o.ObservatoryCode},
+	}
+	if o.Satellite != nil {
+		format := "%c%10.4f"
+		if o.Satellite.Units == 2 {
+			format = "%c%10.8f"
+		}
+		fields = append(fields,
+			formatField{colObsSatelliteUnits, strconv.Itoa(o.Satellite.Units)},
+			formatField{colObsSatelliteX, formatSigned(format, o.Satellite.X)},
+			formatField{colObsSatelliteY, formatSigned(format, o.Satellite.Y)},
+			formatField{colObsSatelliteZ, formatSigned(format, o.Satellite.Z)},
+		)
+	} else {
+		fields = append(fields,
+			formatField{colObsSatelliteUnits, "1"},
+			formatField{colObsRovingLongitude, fmt.Sprintf("%10.6f", o.Roving.Longitude)},
+			formatField{colObsRovingLatitude, fmt.Sprintf("%+10.6f", o.Roving.Latitude)},
+			formatField{colObsRovingAltitude, fmt.Sprintf("%.0f", o.Roving.Altitude)},
+		)
+	}
+	for _, f := range fields {
+		if err := f.c.put(second, f.value); err != nil {
+			return "", err
+		}
+	}
+	return string(line) + "\n" + string(second), nil
+}
+
+/*
+Works out the packed number and designation columns for an observation
+*/
+func packObservationDesignation(o *Observation) (string, string, error) {
+	var number, designation string
+	var err error
+	if o.Number > 0 {
+		number, err = PackDesignation(strconv.FormatInt(o.Number, 10))
+		if err != nil {
+			return "", "", colObsNumber.formatError(err)
+		}
+	}
+	switch {
+	case o.ProvisionalDesignation != "":
+		designation, err = PackDesignation(o.ProvisionalDesignation)
+		if err != nil {
+			return "", "", colObsDesignation.formatError(err)
+		}
+	case o.TemporaryDesignation != "":
+		designation = o.TemporaryDesignation
+	}
+	if number == "" && designation == "" {
+		return "", "", colObsDesignation.formatError(designationError("", "no designation given"))
+	}
+	return number, designation, nil
+}
+
+/*
+The inverse of readObservationTime. The time is rounded to the nearest 1e-5 of
+a day first so the day doesn't round up past the end of the month.
+*/
+func formatObservationTime(t time.Time) string {
+	t = t.UTC().Round(864 * time.Millisecond)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	fraction := float64(t.Sub(midnight)) / float64(24*time.Hour)
+	return fmt.Sprintf("%04d %02d %08.5f", t.Year(), t.Month(), float64(t.Day())+fraction)
+}
+
+/*
+The inverse of readSexagesimal. decimals is the number of places to write the
+seconds to.
+*/
+func formatSexagesimal(value float64, decimals int, signed bool) string {
+	sign := "+"
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	scale := math.Pow(10, float64(decimals))
+	total := int64(math.Floor(value*3600*scale + 0.5))
+	units := total / int64(3600*scale)
+	minutes := (total / int64(60*scale)) % 60
+	seconds := float64(total%int64(60*scale)) / scale
+	width := 2
+	if decimals > 0 {
+		width = decimals + 3
+	}
+	result := fmt.Sprintf("%02d %02d %0*.*f", units, minutes, width, decimals, seconds)
+	if signed {
+		return sign + result
+	}
+	return result
+}
+
+/*
+The second lines have a sign then the number right aligned after it
+*/
+func formatSigned(format string, v float64) string {
+	sign := '+'
+	if v < 0 {
+		sign = '-'
+		v = -v
+	}
+	return fmt.Sprintf(format, sign, v)
+}
@@ -0,0 +1,285 @@
+package gompcreader
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const observationSamplePath = "testdata/Obs_sample.txt"
+
+func readAllObservations(t *testing.T, reader *ObservationReader) []*Observation {
+	var result []*Observation
+	obs, err := reader.ReadEntry()
+	for err == nil {
+		result = append(result, obs)
+		obs, err = reader.ReadEntry()
+	}
+	assert.Equal(t, io.EOF, err)
+	return result
+}
+
+func TestObservationReader(t *testing.T) {
+	reader, err := NewObservationReader(observationSamplePath)
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	obs := readAllObservations(t, reader)
+	assert.Len(t, obs, 6)
+
+	ceres := obs[0]
+	assert.Equal(t, int64(1), ceres.Number)
+	assert.Equal(t, "1", ceres.ID)
+	assert.True(t, ceres.Discovery)
+	assert.Equal(t, "A", ceres.Note2)
+	assert.Equal(t, time.Date(1801, 1, 1, 21, 2, 0, 96000000, time.UTC), ceres.Time)
+	assert.InDelta(t, (3+27.0/60+11.40/3600)*15, ceres.RightAscension, 1e-9)
+	assert.InDelta(t, 11+48.0/60+47.0/3600, ceres.Declination, 1e-9)
+	assert.Equal(t, 0.0, ceres.Magnitude)
+	assert.Equal(t, "", ceres.Band)
+	assert.Equal(t, "000", ceres.ObservatoryCode)
+
+	eros := obs[1]
+	assert.Equal(t, "433", eros.ID)
+	assert.False(t, eros.Discovery)
+	assert.InDelta(t, -(23 + 11.0/60 + 5.12/3600), eros.Declination, 1e-9)
+	assert.Equal(t, 12.3, eros.Magnitude)
+	assert.Equal(t, "V", eros.Band)
+	assert.Equal(t, "568", eros.ObservatoryCode)
+
+	provisional := obs[2]
+	assert.Equal(t, int64(0), provisional.Number)
+	assert.Equal(t, "2014 AB123", provisional.ProvisionalDesignation)
+	assert.Equal(t, "2014 AB123", provisional.ID)
+	assert.Equal(t, "K", provisional.Note1)
+	assert.Nil(t, provisional.Satellite)
+
+	satellite := obs[3]
+	assert.Equal(t, "S", satellite.Note2)
+	assert.Equal(t, &SatellitePosition{Units: 1, X: -3548.621, Y: 5410.711, Z: 1430.342}, satellite.Satellite)
+	assert.Equal(t, "C51", satellite.ObservatoryCode)
+
+	roving := obs[4]
+	assert.Equal(t, "100001", roving.ID)
+	assert.Equal(t, &RovingPosition{Longitude: 249.123456, Latitude: 32.123456, Altitude: 1234}, roving.Roving)
+
+	temporary := obs[5]
+	assert.Equal(t, "TEMP123", temporary.TemporaryDesignation)
+	assert.Equal(t, "TEMP123", temporary.ID)
+}
+
+func TestObservationLinksToMinorPlanet(t *testing.T) {
+	planet, err := ParseRecord(ceresLine)
+	assert.Nil(t, err)
+	obs, err := ParseObservation("00001       * A1801 01 01.87639 03 27 11.40 +11 48 47.0                      000")
+	assert.Nil(t, err)
+	assert.Equal(t, planet.ID, obs.ID)
+}
+
+func TestObservationReaderSkipsRadar(t *testing.T) {
+	input := "00433         R2023 01 15.12345 radar data ignored here                     253\n" +
+		"00433         r2023 01 15.12345 more radar data                              253\n" +
+		"00433         C2023 01 15.12345 05 12 33.123-23 11 05.12          12.3V      568\n"
+	reader, err := NewObservationReaderFromReader(strings.NewReader(input))
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	obs := readAllObservations(t, reader)
+	assert.Len(t, obs, 1)
+	assert.Equal(t, "C", obs[0].Note2)
+}
+
+func TestObservationReaderErrors(t *testing.T) {
+	input := "00433         S2023 01 15.12345 05 12 33.123-23 11 05.12          12.3V      C51\n" +
+		"00433         C2023 01 15.12345 05 12 33.123-23 11 05.12          12.3V      568\n"
+	reader, err := NewObservationReaderFromReader(strings.NewReader(input))
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	_, err = reader.ReadEntry()
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, int64(2), pe.Line)
+	assert.Equal(t, "Note2", pe.Field)
+
+	_, err = ParseObservation("00433         C2023 13 15.12345 05 12 33.123-23 11 05.12          12.3V      568")
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "Time", pe.Field)
+
+	_, err = ParseObservation("00433         C2023 01 15.12345 05 1x 33.123-23 11 05.12          12.3V      568")
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "RightAscension", pe.Field)
+
+	_, err = ParseObservation("00433         C2023 01 15.12345 05 12 33.123-23 11 05.12          1x.3V      568")
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "Magnitude", pe.Field)
+}
+
+var sexagesimalTests = []floatTestCase{
+	{"03 27 11.40", 3 + 27.0/60 + 11.4/3600},
+	{"-23 11 05.12", -(23 + 11.0/60 + 5.12/3600)},
+	{"+00 30", 0.5},
+	{"-00 30 00.0", -0.5},
+	{"12", 12},
+}
+
+func TestReadSexagesimal(t *testing.T) {
+	floatTest(t,
+		"readSexagesimal",
+		sexagesimalTests,
+		readSexagesimal)
+}
+
+func assertSameObservation(t *testing.T, expected *Observation, actual *Observation) {
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.Number, actual.Number)
+	assert.Equal(t, expected.ProvisionalDesignation, actual.ProvisionalDesignation)
+	assert.Equal(t, expected.TemporaryDesignation, actual.TemporaryDesignation)
+	assert.Equal(t, expected.Discovery, actual.Discovery)
+	assert.Equal(t, expected.Note1, actual.Note1)
+	assert.Equal(t, expected.Note2, actual.Note2)
+	assert.Equal(t, expected.Mode, actual.Mode)
+	assert.WithinDuration(t, expected.Time, actual.Time, time.Millisecond)
+	assert.InDelta(t, expected.RightAscension, actual.RightAscension, 1e-5)
+	assert.InDelta(t, expected.Declination, actual.Declination, 1e-5)
+	assert.InDelta(t, expected.Magnitude, actual.Magnitude, 0.05)
+	assert.Equal(t, expected.HasMagnitude(), actual.HasMagnitude())
+	assert.Equal(t, expected.Band, actual.Band)
+	assert.Equal(t, expected.ObservatoryCode, actual.ObservatoryCode)
+	assert.Equal(t, expected.Satellite, actual.Satellite)
+	assert.Equal(t, expected.Roving, actual.Roving)
+}
+
+func TestObservationMagnitude(t *testing.T) {
+	// a measured magnitude of zero with no band
+	zero := "00433         C2023 01 15.12345 05 12 33.123-23 11 05.12           0.0       568"
+	blank := "00433         C2023 01 15.12345 05 12 33.123-23 11 05.12                     568"
+	for _, line := range []string{zero, blank} {
+		obs, err := ParseObservation(line)
+		assert.Nil(t, err)
+		assert.Equal(t, line == zero, obs.HasMagnitude())
+		assert.Equal(t, 0.0, obs.Magnitude)
+
+		formatted, err := FormatObservation(obs)
+		assert.Nil(t, err)
+		assert.Equal(t, line, formatted)
+
+		result, err := observationFromADES(observationToADES(obs))
+		assert.Nil(t, err)
+		assert.Equal(t, obs.HasMagnitude(), result.HasMagnitude())
+	}
+
+	obs, _ := ParseObservation(zero)
+	obs.SetMissing(FieldMagnitude)
+	assert.Equal(t, FieldMagnitude, obs.Missing())
+	formatted, err := FormatObservation(obs)
+	assert.Nil(t, err)
+	assert.Equal(t, blank, formatted)
+}
+
+func TestFormatObservation(t *testing.T) {
+	line := "00433         C2023 01 15.12345 05 12 33.123-23 11 05.12          12.3V      568"
+	obs, err := ParseObservation(line)
+	assert.Nil(t, err)
+	formatted, err := FormatObservation(obs)
+	assert.Nil(t, err)
+	assert.Equal(t, line, formatted)
+
+	line = "     K14AC3B  S2014 01 06.01234 10 01 05.670+05 06 01.20          20.1R      C51\n" +
+		"     K14AC3B  s2014 01 06.01234 1 - 3548.6210 + 5410.7110 + 1430.3420        C51"
+	reader, err := NewObservationReaderFromReader(strings.NewReader(line))
+	assert.Nil(t, err)
+	defer reader.Close()
+	obs, err = reader.ReadEntry()
+	assert.Nil(t, err)
+	formatted, err = FormatObservation(obs)
+	assert.Nil(t, err)
+	assert.Equal(t, line, formatted)
+}
+
+func TestFormatObservationRoundTrip(t *testing.T) {
+	reader, err := NewObservationReader(observationSamplePath)
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	for _, obs := range readAllObservations(t, reader) {
+		formatted, err := FormatObservation(obs)
+		assert.Nil(t, err)
+		second, err := NewObservationReaderFromReader(strings.NewReader(formatted))
+		assert.Nil(t, err)
+		result, err := second.ReadEntry()
+		second.Close()
+		if assert.Nil(t, err, formatted) {
+			assertSameObservation(t, obs, result)
+		}
+	}
+}
+
+func TestFormatObservationErrors(t *testing.T) {
+	_, err := FormatObservation(&Observation{ObservatoryCode: "568"})
+	assert.True(t, errors.Is(err, ErrInvalidDesignation))
+
+	_, err = FormatObservation(&Observation{TemporaryDesignation: "TOOLONGNAME", ObservatoryCode: "568"})
+	assert.True(t, errors.Is(err, ErrFieldWidth))
+}
+
+var formatSexagesimalTests = []struct {
+	value    float64
+	decimals int
+	signed   bool
+	out      string
+}{
+	{3 + 27.0/60 + 11.4/3600, 3, false, "03 27 11.400"},
+	{-(23 + 11.0/60 + 5.12/3600), 2, true, "-23 11 05.12"},
+	{0.5, 1, true, "+00 30 00.0"},
+	{12 + 59.0/60 + 59.6/3600, 0, false, "13 00 00"},
+	{-(3 + 33.0/60 + 4.4/3600), 0, true, "-03 33 04"},
+}
+
+func TestFormatSexagesimal(t *testing.T) {
+	for _, tt := range formatSexagesimalTests {
+		assert.Equal(t, tt.out, formatSexagesimal(tt.value, tt.decimals, tt.signed))
+	}
+}
+
+var note2ModeTests = []struct {
+	note2 string
+	mode  string
+	back  string
+}{
+	{"C", "CCD", "C"},
+	{"B", "CMO", "B"},
+	{"P", "PHO", "P"},
+	{"A", "UNK", ""},
+	{"e", "ENC", "e"},
+	{"T", "MER", "T"},
+	{"M", "MIC", "M"},
+	{"c", "CCD", "C"},
+	{"n", "VID", "n"},
+	{"E", "OCC", "E"},
+}
+
+func TestNote2ModeRoundTrip(t *testing.T) {
+	for _, tt := range note2ModeTests {
+		line := "00433         " + tt.note2 + "2023 01 15.12345 05 12 33.123-23 11 05.12          12.3V      568"
+		obs, err := ParseObservation(line)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.mode, obs.Mode, "mode of Note2 %q", tt.note2)
+
+		// through ADES, which only keeps the mode
+		result, err := observationFromADES(observationToADES(obs))
+		assert.Nil(t, err)
+		assert.Equal(t, tt.mode, result.Mode)
+		assert.Equal(t, tt.back, result.Note2, "Note2 of mode %q", tt.mode)
+
+		formatted, err := FormatObservation(result)
+		assert.Nil(t, err)
+		again, err := ParseObservation(formatted)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.mode, again.Mode)
+	}
+}
@@ -0,0 +1,252 @@
+package gompcreader
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+/*
+Observatory is an entry from the MPC list of observatory codes, ObsCodes.txt,
+from https://www.minorplanetcenter.net/iau/lists/ObsCodes.html
+
+Longitude is in degrees east of Greenwich. RhoCosPhi and RhoSinPhi are the
+parallax constants, the distance from the centre of the Earth in equatorial
+radii multiplied by the cosine and sine of the geocentric latitude.
+
+Space based and roving codes don't have a fixed position so HasParallax is
+false and the other numbers are zero.
+*/
+type Observatory struct {
+	Code        string
+	Longitude   float64
+	RhoCosPhi   float64
+	RhoSinPhi   float64
+	Name        string
+	HasParallax bool
+}
+
+/*
+ObservatoryReader reads entries from the observatory codes file. Both the
+plain text and html versions can be read. It works in the same way as
+MpcReader, construct it with NewObservatoryReader(string) and call ReadEntry()
+until it returns io.EOF, then Close() it.
+*/
+type ObservatoryReader struct {
+	src *lineSource
+}
+
+/*
+NewObservatoryReader opens the observatory codes file at filePath.
+Compression is detected in the same way as NewMpcReader.
+*/
+func NewObservatoryReader(filePath string) (*ObservatoryReader, error) {
+	src, err := openLineSource(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return &ObservatoryReader{src: src}, nil
+}
+
+/*
+NewObservatoryReaderFromReader creates an observatory reader that reads from
+in. Closing the returned reader will not close in.
+*/
+func NewObservatoryReaderFromReader(in io.Reader) (*ObservatoryReader, error) {
+	src, err := newLineSource(in, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ObservatoryReader{src: src}, nil
+}
+
+/*
+NewObservatoryReaderFromReadCloser creates an observatory reader that takes
+ownership of in and closes it when the reader is closed.
+*/
+func NewObservatoryReaderFromReadCloser(in io.ReadCloser) (*ObservatoryReader, error) {
+	src, err := newLineSource(in, in)
+	if err != nil {
+		return nil, err
+	}
+	return &ObservatoryReader{src: src}, nil
+}
+
+/*
+ReadEntry returns the next observatory from the file. Blank lines, the column
+header and any html tags on their own line are skipped.
+
+If the record could not be converted the error will be a *ParseError. This
+will return io.EOF when the end of the file is reached.
+*/
+func (reader *ObservatoryReader) ReadEntry() (*Observatory, error) {
+	for {
+		line, err := reader.src.next()
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimPrefix(line, "<pre>")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "<") || strings.HasPrefix(trimmed, "Code") {
+			continue
+		}
+		result, err := ParseObservatory(line)
+		if err != nil {
+			return nil, reader.src.locate(err)
+		}
+		return result, nil
+	}
+}
+
+/*
+Close the reader down. This will clean up the open file handle.
+*/
+func (reader *ObservatoryReader) Close() {
+	reader.src.close()
+}
+
+const observatoryRecordLength = 30
+
+var (
+	colObservatoryCode      = column{"Code", 0, 3}
+	colObservatoryLongitude = column{"Longitude", 3, 12}
+	colObservatoryRhoCosPhi = column{"RhoCosPhi", 12, 20}
+	colObservatoryRhoSinPhi = column{"RhoSinPhi", 20, 29}
+	colObservatoryParallax  = column{"Longitude", 3, 29}
+)
+
+/*
+ParseObservatory converts a single line of the observatory codes file into an
+Observatory.
+*/
+func ParseObservatory(line string) (*Observatory, error) {
+	buffer := strings.TrimRight(line, "\r\n")
+	if len(buffer) < observatoryRecordLength {
+		buffer += strings.Repeat(" ", observatoryRecordLength-len(buffer))
+	}
+
+	var r Observatory
+	var err error
+
+	if r.Code, err = colObservatoryCode.readString(buffer); err != nil {
+		return nil, err
+	}
+	if len(r.Code) != 3 {
+		return nil, colObservatoryCode.error(buffer, ErrFieldWidth)
+	}
+	r.Name = strings.TrimSpace(buffer[colObservatoryRhoSinPhi.end:])
+
+	if parallax, _ := colObservatoryParallax.readString(buffer); parallax == "" {
+		return &r, nil
+	}
+	r.HasParallax = true
+	if r.Longitude, err = colObservatoryLongitude.readFloat(buffer); err != nil {
+		return nil, err
+	}
+	if r.RhoCosPhi, err = colObservatoryRhoCosPhi.readFloat(buffer); err != nil {
+		return nil, err
+	}
+	if r.RhoSinPhi, err = colObservatoryRhoSinPhi.readFloat(buffer); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+/*
+PositionAt returns the geocentric position of the observatory at t, in AU,
+in the J2000 equatorial frame. t is UTC, which is used in place of UT1.
+
+The Earth's rotation is taken from the mean sidereal time, which gives the
+position on the mean equator of date, and that is precessed back to J2000
+with the IAU 1976 model. Nutation is ignored, which moves the position by
+less than a kilometre.
+*/
+func (o *Observatory) PositionAt(t time.Time) (Vector, error) {
+	if !o.HasParallax {
+		return Vector{}, fmt.Errorf("%w: %s", ErrNoObservatoryPosition, o.Code)
+	}
+	theta := greenwichMeanSiderealTime(t) + radians(o.Longitude)
+	scale := earthEquatorialRadius / AstronomicalUnit
+	ofDate := Vector{
+		X: o.RhoCosPhi * math.Cos(theta) * scale,
+		Y: o.RhoCosPhi * math.Sin(theta) * scale,
+		Z: o.RhoSinPhi * scale,
+	}
+	return precessToJ2000(ofDate, t), nil
+}
+
+/*
+Observatories holds a full list of observatory codes, indexed by code.
+*/
+type Observatories map[string]*Observatory
+
+/*
+ReadObservatories reads every entry from the reader into an Observatories
+map. The reader is not closed.
+*/
+func ReadObservatories(reader *ObservatoryReader) (Observatories, error) {
+	result := make(Observatories)
+	for {
+		o, err := reader.ReadEntry()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[o.Code] = o
+	}
+}
+
+/*
+Lookup returns the geocentric position of the observatory with the given code
+at t, see Observatory.PositionAt. An error wrapping ErrUnknownObservatory is
+returned if the code isn't in the list.
+*/
+func (list Observatories) Lookup(code string, t time.Time) (Vector, error) {
+	o, ok := list[code]
+	if !ok {
+		return Vector{}, fmt.Errorf("%w: %q", ErrUnknownObservatory, code)
+	}
+	return o.PositionAt(t)
+}
+
+/*
+ObserverPosition returns the geocentric position, in AU, of the observer that
+made an observation. Satellite and roving observations carry their own
+position, anything else is looked up from the observatory code.
+*/
+func (list Observatories) ObserverPosition(o *Observation) (Vector, error) {
+	switch {
+	case o.Satellite != nil:
+		p := Vector{X: o.Satellite.X, Y: o.Satellite.Y, Z: o.Satellite.Z}
+		if o.Satellite.Units == 1 {
+			p = Vector{X: p.X / AstronomicalUnit, Y: p.Y / AstronomicalUnit, Z: p.Z / AstronomicalUnit}
+		}
+		return p, nil
+	case o.Roving != nil:
+		return rovingObservatory(o.Roving).PositionAt(o.Time)
+	}
+	return list.Lookup(o.ObservatoryCode, o.Time)
+}
+
+/*
+Works out the parallax constants for a roving observer from its WGS84
+geodetic position.
+*/
+func rovingObservatory(r *RovingPosition) *Observatory {
+	const flattening = 1 / 298.257223563
+	lat := radians(r.Latitude)
+	b := (1 - flattening) * (1 - flattening)
+	c := 1 / math.Sqrt(math.Cos(lat)*math.Cos(lat)+b*math.Sin(lat)*math.Sin(lat))
+	h := r.Altitude / 1000 / earthEquatorialRadius
+	return &Observatory{
+		Code:        "247",
+		Longitude:   r.Longitude,
+		RhoCosPhi:   (c + h) * math.Cos(lat),
+		RhoSinPhi:   (b*c + h) * math.Sin(lat),
+		HasParallax: true,
+	}
+}
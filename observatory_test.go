@@ -0,0 +1,166 @@
+package gompcreader
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const observatorySamplePath = "testdata/ObsCodes_sample.html"
+
+func readSampleObservatories(t *testing.T) Observatories {
+	reader, err := NewObservatoryReader(observatorySamplePath)
+	assert.Nil(t, err)
+	defer reader.Close()
+	list, err := ReadObservatories(reader)
+	assert.Nil(t, err)
+	return list
+}
+
+func TestObservatoryReader(t *testing.T) {
+	list := readSampleObservatories(t)
+	assert.Len(t, list, 7)
+
+	assert.Equal(t, &Observatory{
+		Code:        "568",
+		Longitude:   204.5278,
+		RhoCosPhi:   0.94171,
+		RhoSinPhi:   0.33725,
+		Name:        "Mauna Kea",
+		HasParallax: true,
+	}, list["568"])
+	assert.Equal(t, "Pan-STARRS 1, Haleakala", list["F51"].Name)
+	assert.Equal(t, &Observatory{Code: "245", Name: "Spitzer Space Telescope"}, list["245"])
+	assert.True(t, list["500"].HasParallax)
+}
+
+func TestParseObservatoryErrors(t *testing.T) {
+	_, err := ParseObservatory("000   0.0000 0.6x411 +0.77873 Greenwich")
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "RhoCosPhi", pe.Field)
+
+	reader, err := NewObservatoryReaderFromReader(strings.NewReader("Code  Long.   cos      sin    Name\n" +
+		"000   0.0000 0.62411 +0.77873 Greenwich\n" +
+		"00    0.0000 0.62411 +0.77873 Greenwich\n"))
+	assert.Nil(t, err)
+	defer reader.Close()
+	_, err = reader.ReadEntry()
+	assert.Nil(t, err)
+	_, err = reader.ReadEntry()
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, int64(3), pe.Line)
+	assert.Equal(t, "Code", pe.Field)
+}
+
+func TestObservatoryLookup(t *testing.T) {
+	list := readSampleObservatories(t)
+	j2000 := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+	scale := earthEquatorialRadius / AstronomicalUnit
+
+	// At J2000.0 the mean sidereal time at Greenwich was 18h 41m 50.548s
+	gmst := (18 + 41.0/60 + 50.548/3600) * 15
+
+	var lookupTests = []struct {
+		code string
+		t    time.Time
+		lst  float64
+	}{
+		{"000", j2000, gmst},
+		{"568", j2000, gmst + 204.5278},
+		// a sidereal day later the Earth is back where it started
+		{"000", j2000.Add(86164090 * time.Millisecond), gmst},
+		// and a quarter of one later it has turned 90 degrees
+		{"000", j2000.Add(86164090 * time.Millisecond / 4), gmst + 90},
+	}
+	for _, tt := range lookupTests {
+		o := list[tt.code]
+		pos, err := list.Lookup(tt.code, tt.t)
+		assert.Nil(t, err)
+		pos = precessFromJ2000(pos, tt.t)
+		assert.InDelta(t, math.Hypot(o.RhoCosPhi, o.RhoSinPhi)*scale, pos.Norm(), 1e-15)
+		assert.InDelta(t, o.RhoSinPhi*scale, pos.Z, 1e-15)
+		lst := math.Mod(degrees(math.Atan2(pos.Y, pos.X))-tt.lst+720, 360)
+		if lst > 180 {
+			lst -= 360
+		}
+		assert.InDelta(t, 0, lst, 1e-3, "%s at %s", tt.code, tt.t)
+	}
+
+	pos, err := list.Lookup("500", j2000)
+	assert.Nil(t, err)
+	assert.Equal(t, Vector{}, pos)
+
+	_, err = list.Lookup("C51", j2000)
+	assert.True(t, errors.Is(err, ErrNoObservatoryPosition))
+
+	_, err = list.Lookup("ZZZ", j2000)
+	assert.True(t, errors.Is(err, ErrUnknownObservatory))
+}
+
+func TestObserverPosition(t *testing.T) {
+	list := readSampleObservatories(t)
+	when := time.Date(2020, 5, 12, 12, 0, 0, 0, time.UTC)
+
+	fixed, err := list.ObserverPosition(&Observation{ObservatoryCode: "568", Time: when})
+	assert.Nil(t, err)
+	expected, _ := list.Lookup("568", when)
+	assert.Equal(t, expected, fixed)
+
+	satellite, err := list.ObserverPosition(&Observation{
+		ObservatoryCode: "C51",
+		Satellite:       &SatellitePosition{Units: 1, X: -3548.621, Y: 5410.711, Z: 1430.342},
+	})
+	assert.Nil(t, err)
+	assert.InDelta(t, -3548.621/AstronomicalUnit, satellite.X, 1e-15)
+
+	// A roving observer on the equator at sea level is one equatorial radius
+	// from the centre, and at the pole one polar radius.
+	roving, err := list.ObserverPosition(&Observation{
+		ObservatoryCode: "247",
+		Roving:          &RovingPosition{Longitude: 10},
+		Time:            when,
+	})
+	assert.Nil(t, err)
+	assert.InDelta(t, earthEquatorialRadius, roving.Norm()*AstronomicalUnit, 1e-6)
+
+	roving, err = list.ObserverPosition(&Observation{
+		ObservatoryCode: "247",
+		Roving:          &RovingPosition{Latitude: 90, Altitude: 1000},
+		Time:            when,
+	})
+	assert.Nil(t, err)
+	assert.InDelta(t, 6356.752+1, precessFromJ2000(roving, when).Z*AstronomicalUnit, 1e-3)
+}
+
+func TestPrecession(t *testing.T) {
+	// Meeus, Astronomical Algorithms example 21.b, theta Persei
+	when := time.Date(2028, 11, 13, 4, 33, 36, 0, time.UTC)
+	j2000 := equatorialVector(41.054063, 49.227750)
+	ofDate := precessFromJ2000(j2000, when)
+	assert.InDelta(t, 41.547214, degrees(math.Atan2(ofDate.Y, ofDate.X)), 1e-5)
+	assert.InDelta(t, 49.348483, degrees(math.Asin(ofDate.Z)), 1e-5)
+
+	back := precessToJ2000(ofDate, when)
+	assert.InDelta(t, 0, back.Sub(j2000).Norm(), 1e-15)
+
+	// by 2026 ignoring it would move a site on the equator by tens of km
+	list := readSampleObservatories(t)
+	when = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	pos, err := list.Lookup("000", when)
+	assert.Nil(t, err)
+	moved := precessFromJ2000(pos, when).Sub(pos).Norm() * AstronomicalUnit
+	assert.True(t, moved > 30 && moved < 50, "moved %v km", moved)
+}
+
+func equatorialVector(ra, dec float64) Vector {
+	return Vector{
+		X: math.Cos(radians(dec)) * math.Cos(radians(ra)),
+		Y: math.Cos(radians(dec)) * math.Sin(radians(ra)),
+		Z: math.Sin(radians(dec)),
+	}
+}
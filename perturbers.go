@@ -0,0 +1,169 @@
+package gompcreader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+Body is a solar system body that can be included as a perturber when an orbit
+is computed.
+*/
+type Body int
+
+// The bodies that can appear in the perturber indicators
+const (
+	Mercury Body = iota
+	Venus
+	Earth
+	Mars
+	Jupiter
+	Saturn
+	Uranus
+	Neptune
+	Pluto
+	Ceres
+	Pallas
+	Vesta
+)
+
+var bodyNames = []string{
+	"Mercury", "Venus", "Earth", "Mars", "Jupiter", "Saturn", "Uranus",
+	"Neptune", "Pluto", "Ceres", "Pallas", "Vesta",
+}
+
+func (b Body) String() string {
+	if b < 0 || int(b) >= len(bodyNames) {
+		return "Unknown"
+	}
+	return bodyNames[b]
+}
+
+/*
+Perturbers is a set of perturbing bodies
+*/
+type Perturbers uint32
+
+/*
+PerturbersOf builds a set from a list of bodies
+*/
+func PerturbersOf(bodies ...Body) Perturbers {
+	var p Perturbers
+	for _, b := range bodies {
+		p = p | 1<<uint(b)
+	}
+	return p
+}
+
+/*
+Has reports if the body is in the set
+*/
+func (p Perturbers) Has(b Body) bool {
+	return p&(1<<uint(b)) != 0
+}
+
+/*
+Bodies lists the members of the set in order from the sun outwards, with the
+asteroids last.
+*/
+func (p Perturbers) Bodies() []Body {
+	var result []Body
+	for b := Mercury; b <= Vesta; b++ {
+		if p.Has(b) {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+func (p Perturbers) String() string {
+	var names []string
+	for _, b := range p.Bodies() {
+		names = append(names, b.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+var majorPlanets = PerturbersOf(Mercury, Venus, Earth, Mars, Jupiter, Saturn, Uranus, Neptune)
+
+/*
+The coarse indicator codes and the bodies they stand for.
+*/
+var coarsePerturbers = map[string]Perturbers{
+	"M-h": majorPlanets,
+	"M-p": majorPlanets | PerturbersOf(Pluto),
+	"M-v": majorPlanets | PerturbersOf(Ceres, Pallas, Vesta),
+}
+
+/*
+ParseCoarsePerturbers decodes the coarse indicator of perturbers from columns
+143-145. "M-h" is Mercury to Neptune, "M-p" adds Pluto and "M-v" adds Ceres,
+Pallas and Vesta to the planets. A blank code is an unperturbed orbit and
+returns an empty set.
+*/
+func ParseCoarsePerturbers(code string) (Perturbers, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return 0, nil
+	}
+	p, ok := coarsePerturbers[code]
+	if !ok {
+		return 0, fmt.Errorf("unknown coarse perturber code %q", code)
+	}
+	return p, nil
+}
+
+/*
+PrecisePerturbers is the precise indicator of perturbers from columns 147-149.
+It is written as two hex digits followed by "h", each bit marking one of the
+planets from Mercury (bit 0) to Neptune (bit 7) as used in the orbit solution.
+*/
+type PrecisePerturbers uint8
+
+/*
+ParsePrecisePerturbers decodes a precise indicator such as "30h". A blank code
+is an unperturbed orbit and returns zero.
+*/
+func ParsePrecisePerturbers(code string) (PrecisePerturbers, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return 0, nil
+	}
+	if len(code) != 3 || code[2] != 'h' {
+		return 0, fmt.Errorf("precise perturber code %q is not two hex digits followed by h", code)
+	}
+	v, err := strconv.ParseUint(code[:2], 16, 8)
+	if err != nil {
+		return 0, err
+	}
+	return PrecisePerturbers(v), nil
+}
+
+/*
+Perturbers returns the set of bodies marked in the indicator
+*/
+func (p PrecisePerturbers) Perturbers() Perturbers {
+	return Perturbers(p)
+}
+
+/*
+String returns the indicator in the same form as the file, "30h"
+*/
+func (p PrecisePerturbers) String() string {
+	return fmt.Sprintf("%02Xh", uint8(p))
+}
+
+/*
+CoarsePerturbers decodes CoarseIndicatorOfPerturbers
+*/
+func (p *MinorPlanet) CoarsePerturbers() (Perturbers, error) {
+	return ParseCoarsePerturbers(p.CoarseIndicatorOfPerturbers)
+}
+
+/*
+PrecisePerturbers decodes PreciseIndicatorOfPerturbers
+*/
+func (p *MinorPlanet) PrecisePerturbers() (PrecisePerturbers, error) {
+	return ParsePrecisePerturbers(p.PreciseIndicatorOfPerturbers)
+}
@@ -0,0 +1,76 @@
+package gompcreader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCoarsePerturbers(t *testing.T) {
+	p, err := ParseCoarsePerturbers("M-v")
+	assert.Nil(t, err)
+	assert.True(t, p.Has(Mercury))
+	assert.True(t, p.Has(Neptune))
+	assert.False(t, p.Has(Pluto))
+	assert.True(t, p.Has(Vesta))
+	assert.Equal(t, "Mercury, Venus, Earth, Mars, Jupiter, Saturn, Uranus, Neptune, Ceres, Pallas, Vesta", p.String())
+
+	p, err = ParseCoarsePerturbers("M-p")
+	assert.Nil(t, err)
+	assert.True(t, p.Has(Pluto))
+	assert.False(t, p.Has(Ceres))
+
+	p, err = ParseCoarsePerturbers("M-h")
+	assert.Nil(t, err)
+	assert.Len(t, p.Bodies(), 8)
+
+	p, err = ParseCoarsePerturbers("   ")
+	assert.Nil(t, err)
+	assert.Equal(t, Perturbers(0), p)
+
+	_, err = ParseCoarsePerturbers("X-y")
+	assert.NotNil(t, err)
+}
+
+var precisePerturbersTests = []struct {
+	in     string
+	bodies []Body
+}{
+	{"30h", []Body{Jupiter, Saturn}},
+	{"3Eh", []Body{Venus, Earth, Mars, Jupiter, Saturn}},
+	{"FFh", []Body{Mercury, Venus, Earth, Mars, Jupiter, Saturn, Uranus, Neptune}},
+	{"00h", nil},
+}
+
+func TestParsePrecisePerturbers(t *testing.T) {
+	for _, tt := range precisePerturbersTests {
+		p, err := ParsePrecisePerturbers(tt.in)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.bodies, p.Perturbers().Bodies(), "bodies of %s", tt.in)
+		assert.Equal(t, tt.in, p.String())
+	}
+
+	for _, tt := range []string{"30", "3G", "30x", "123h"} {
+		_, err := ParsePrecisePerturbers(tt)
+		assert.NotNil(t, err, "ParsePrecisePerturbers(%q)", tt)
+	}
+}
+
+func TestMinorPlanetPerturbers(t *testing.T) {
+	result, err := ParseRecord(ceresLine)
+	assert.Nil(t, err)
+
+	coarse, err := result.CoarsePerturbers()
+	assert.Nil(t, err)
+	assert.True(t, coarse.Has(Vesta))
+
+	precise, err := result.PrecisePerturbers()
+	assert.Nil(t, err)
+	assert.Equal(t, result.PreciseIndicatorOfPerturbers, precise.String())
+
+	result, err = ParseRecord(t3s5154Line)
+	assert.Nil(t, err)
+	coarse, err = result.CoarsePerturbers()
+	assert.Nil(t, err)
+	assert.Equal(t, Perturbers(0), coarse)
+}
@@ -0,0 +1,213 @@
+/*
+Package photometry provides the phase functions used to predict the brightness
+of minor planets, and estimates of their size from their absolute magnitude.
+
+Phase angles are in degrees and distances in AU, the same as the rest of
+gompcreader.
+*/
+package photometry
+
+import (
+	"math"
+)
+
+// DefaultSlope is the slope parameter G the MPC assumes when none has been
+// measured.
+const DefaultSlope = 0.15
+
+// DefaultAlbedo is a typical geometric albedo for a minor planet whose type
+// isn't known. It is the value usually used to turn the H of a near earth
+// object into a size.
+const DefaultAlbedo = 0.14
+
+/*
+Model is a phase function. ReducedMagnitude returns the magnitude the object
+would have one AU from both the Sun and the observer, at phase angle alpha in
+degrees.
+*/
+type Model interface {
+	ReducedMagnitude(alpha float64) float64
+}
+
+/*
+ApparentMagnitude returns the magnitude of an object r AU from the Sun and
+delta AU from the observer, seen at phase angle alpha degrees.
+*/
+func ApparentMagnitude(m Model, r float64, delta float64, alpha float64) float64 {
+	return m.ReducedMagnitude(alpha) + 5*math.Log10(r*delta)
+}
+
+/*
+Diameter estimates the diameter in km of an object with absolute magnitude h
+and geometric albedo, using D = 1329 / sqrt(albedo) * 10^(-h/5).
+*/
+func Diameter(h float64, albedo float64) float64 {
+	return 1329 / math.Sqrt(albedo) * math.Pow(10, -h/5)
+}
+
+/*
+HG is the IAU two parameter magnitude system, from Bowell et al. (1989). This
+is the system the MPC uses for the H and G in MPCORB.DAT.
+*/
+type HG struct {
+	H float64
+	G float64
+}
+
+/*
+ReducedMagnitude uses the approximate form of the H-G phase function.
+*/
+func (m HG) ReducedMagnitude(alpha float64) float64 {
+	tanHalf := math.Tan(radians(alpha) / 2)
+	phi1 := math.Exp(-3.33 * math.Pow(tanHalf, 0.63))
+	phi2 := math.Exp(-1.87 * math.Pow(tanHalf, 1.22))
+	return m.H - 2.5*math.Log10((1-m.G)*phi1+m.G*phi2)
+}
+
+/*
+HG1G2 is the three parameter magnitude system from Muinonen et al. (2010),
+using the basis functions as corrected by Penttilä et al. (2016). It is only
+defined for phase angles up to 150 degrees, beyond that the result is NaN.
+*/
+type HG1G2 struct {
+	H  float64
+	G1 float64
+	G2 float64
+}
+
+/*
+ReducedMagnitude returns the H-G1-G2 magnitude at phase angle alpha degrees.
+*/
+func (m HG1G2) ReducedMagnitude(alpha float64) float64 {
+	a := radians(alpha)
+	phi := m.G1*phi1(a) + m.G2*phi2(a) + (1-m.G1-m.G2)*phi3(a)
+	return m.H - 2.5*math.Log10(phi)
+}
+
+/*
+HG12 is the two parameter version of H-G1-G2 from Muinonen et al. (2010),
+intended for objects with too few observations to fit G1 and G2 separately.
+*/
+type HG12 struct {
+	H   float64
+	G12 float64
+}
+
+/*
+HG1G2 converts to the equivalent three parameter model.
+*/
+func (m HG12) HG1G2() HG1G2 {
+	if m.G12 < 0.2 {
+		return HG1G2{H: m.H, G1: 0.7527*m.G12 + 0.06164, G2: -0.9612*m.G12 + 0.6270}
+	}
+	return HG1G2{H: m.H, G1: 0.9529*m.G12 + 0.02162, G2: -0.6125*m.G12 + 0.5572}
+}
+
+/*
+ReducedMagnitude returns the H-G12 magnitude at phase angle alpha degrees.
+*/
+func (m HG12) ReducedMagnitude(alpha float64) float64 {
+	return m.HG1G2().ReducedMagnitude(alpha)
+}
+
+// The H-G1-G2 basis functions are linear below 7.5 degrees and cubic splines
+// through these points above it. phi3 is a spline all the way from zero. The
+// values are from Penttilä et al. (2016), which corrects the derivative of
+// phi3 at zero phase that Muinonen et al. (2010) gave a factor of ten too
+// small.
+var (
+	phi1Spline = newSpline(
+		[]float64{7.5, 30, 60, 90, 120, 150},
+		[]float64{7.5e-1, 3.3486016e-1, 1.3410560e-1, 5.1104756e-2, 2.1465687e-2, 3.6396989e-3},
+		-1.9098593, -9.1328612e-2)
+	phi2Spline = newSpline(
+		[]float64{7.5, 30, 60, 90, 120, 150},
+		[]float64{9.25e-1, 6.2884169e-1, 3.1755495e-1, 1.2716367e-1, 2.2373903e-2, 1.6505689e-4},
+		-5.7295780e-1, -8.6573138e-8)
+	phi3Spline = newSpline(
+		[]float64{0, 0.3, 1, 2, 4, 8, 12, 20, 30},
+		[]float64{1, 8.3381185e-1, 5.7735424e-1, 4.2144772e-1, 2.3174230e-1, 1.0348178e-1, 6.1733473e-2, 1.6107006e-2, 0},
+		-1.0630097, 0)
+)
+
+func phi1(a float64) float64 {
+	if a < radians(7.5) {
+		return 1 - 6*a/math.Pi
+	}
+	return phi1Spline.at(a)
+}
+
+func phi2(a float64) float64 {
+	if a < radians(7.5) {
+		return 1 - 9*a/(5*math.Pi)
+	}
+	return phi2Spline.at(a)
+}
+
+func phi3(a float64) float64 {
+	if a > radians(30) {
+		return 0
+	}
+	return phi3Spline.at(a)
+}
+
+/*
+spline is a cubic spline with the first derivative fixed at each end. The
+knots are given in degrees but the spline works in radians.
+*/
+type spline struct {
+	x, y, y2 []float64
+}
+
+/*
+Builds the spline by solving for the second derivatives at each knot, see
+Numerical Recipes section 3.3.
+*/
+func newSpline(knots []float64, y []float64, start float64, end float64) *spline {
+	n := len(knots)
+	x := make([]float64, n)
+	for i, k := range knots {
+		x[i] = radians(k)
+	}
+	y2 := make([]float64, n)
+	u := make([]float64, n)
+
+	y2[0] = -0.5
+	u[0] = 3 / (x[1] - x[0]) * ((y[1]-y[0])/(x[1]-x[0]) - start)
+	for i := 1; i < n-1; i++ {
+		sig := (x[i] - x[i-1]) / (x[i+1] - x[i-1])
+		p := sig*y2[i-1] + 2
+		y2[i] = (sig - 1) / p
+		u[i] = (y[i+1]-y[i])/(x[i+1]-x[i]) - (y[i]-y[i-1])/(x[i]-x[i-1])
+		u[i] = (6*u[i]/(x[i+1]-x[i-1]) - sig*u[i-1]) / p
+	}
+	un := 3 / (x[n-1] - x[n-2]) * (end - (y[n-1]-y[n-2])/(x[n-1]-x[n-2]))
+	y2[n-1] = (un - 0.5*u[n-2]) / (0.5*y2[n-2] + 1)
+	for k := n - 2; k >= 0; k-- {
+		y2[k] = y2[k]*y2[k+1] + u[k]
+	}
+	return &spline{x: x, y: y, y2: y2}
+}
+
+/*
+Evaluates the spline at a, in radians. Outside the knots the result is NaN.
+*/
+func (s *spline) at(a float64) float64 {
+	n := len(s.x)
+	if a < s.x[0] || a > s.x[n-1] {
+		return math.NaN()
+	}
+	hi := 1
+	for hi < n-1 && s.x[hi] < a {
+		hi++
+	}
+	lo := hi - 1
+	h := s.x[hi] - s.x[lo]
+	p := (s.x[hi] - a) / h
+	q := (a - s.x[lo]) / h
+	return p*s.y[lo] + q*s.y[hi] + ((p*p*p-p)*s.y2[lo]+(q*q*q-q)*s.y2[hi])*h*h/6
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
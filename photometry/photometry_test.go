@@ -0,0 +1,112 @@
+package photometry
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHG(t *testing.T) {
+	m := HG{H: 3.34, G: 0.12}
+	assert.Equal(t, 3.34, m.ReducedMagnitude(0))
+
+	// worked through by hand from the H-G formula
+	assert.InDelta(t, 1.0, HG{H: 0, G: 0.15}.ReducedMagnitude(20), 1e-3)
+
+	// a higher G means a flatter phase curve
+	assert.True(t, HG{G: 0.4}.ReducedMagnitude(20) < HG{G: 0.15}.ReducedMagnitude(20))
+}
+
+func TestApparentMagnitude(t *testing.T) {
+	m := HG{H: 10, G: 0.15}
+	assert.Equal(t, 10.0, ApparentMagnitude(m, 1, 1, 0))
+	assert.InDelta(t, 10+5*math.Log10(6)+m.ReducedMagnitude(10)-10, ApparentMagnitude(m, 3, 2, 10), 1e-12)
+}
+
+func TestHG1G2BasisFunctions(t *testing.T) {
+	// all of the basis functions are one at zero phase
+	assert.InDelta(t, 0, HG1G2{G1: 0.3, G2: 0.3}.ReducedMagnitude(0), 1e-12)
+
+	// the linear part meets the spline at 7.5 degrees
+	below := radians(7.5 - 1e-9)
+	above := radians(7.5 + 1e-9)
+	assert.InDelta(t, phi1(below), phi1(above), 1e-8)
+	assert.InDelta(t, phi2(below), phi2(above), 1e-8)
+
+	// and the splines go through their knots
+	assert.InDelta(t, 3.3486016e-1, phi1(radians(30)), 1e-12)
+	assert.InDelta(t, 3.1755495e-1, phi2(radians(60)), 1e-12)
+	assert.InDelta(t, 4.2144772e-1, phi3(radians(2)), 1e-12)
+	assert.Equal(t, 0.0, phi3(radians(45)))
+
+	// they should all fall as the phase angle grows
+	for a := 0.5; a < 150; a += 0.5 {
+		assert.True(t, phi1(radians(a)) < phi1(radians(a-0.5)), "phi1 at %g", a)
+		assert.True(t, phi2(radians(a)) < phi2(radians(a-0.5)), "phi2 at %g", a)
+		if a <= 30 {
+			assert.True(t, phi3(radians(a)) < phi3(radians(a-0.5)), "phi3 at %g", a)
+		}
+	}
+
+	assert.True(t, math.IsNaN(HG1G2{H: 10, G1: 0.3, G2: 0.3}.ReducedMagnitude(160)))
+}
+
+/*
+Reference magnitudes at phase angles where every basis function is either
+linear or at one of its knots, so they follow directly from the tabulated
+values in Penttilä et al. (2016) without any interpolation.
+*/
+var referenceMagnitudeTests = []struct {
+	alpha float64
+	hg1g2 float64
+	hg12  float64
+}{
+	{0.3, 10.051704, 15.052773},
+	{1, 10.143122, 15.145213},
+	{2, 10.219426, 15.220015},
+	{4, 10.345639, 15.340600},
+	{30, 11.323051, 16.221657},
+	{60, 12.235346, 17.085522},
+	{90, 13.263753, 18.103374},
+	{120, 14.460175, 19.469122},
+	{150, 16.605296, 21.829582},
+}
+
+func TestReferenceMagnitudes(t *testing.T) {
+	for _, tt := range referenceMagnitudeTests {
+		assert.InDelta(t, tt.hg1g2, HG1G2{H: 10, G1: 0.62, G2: 0.14}.ReducedMagnitude(tt.alpha), 1e-6, "H-G1-G2 at %g", tt.alpha)
+		assert.InDelta(t, tt.hg12, HG12{H: 15, G12: 0.5}.ReducedMagnitude(tt.alpha), 1e-6, "H-G12 at %g", tt.alpha)
+	}
+
+	// the slope of phi3 at zero phase, which the knots alone don't pin down
+	a := 1e-8
+	assert.InDelta(t, -1.0630097, (phi3(a)-phi3(0))/a, 1e-3)
+}
+
+var hg12Tests = []struct {
+	g12    float64
+	g1, g2 float64
+}{
+	{0.1, 0.13691, 0.53088},
+	{0.5, 0.49807, 0.25095},
+}
+
+func TestHG12(t *testing.T) {
+	for _, tt := range hg12Tests {
+		m := HG12{H: 15, G12: tt.g12}.HG1G2()
+		assert.Equal(t, 15.0, m.H)
+		assert.InDelta(t, tt.g1, m.G1, 1e-9)
+		assert.InDelta(t, tt.g2, m.G2, 1e-9)
+		assert.Equal(t, m.ReducedMagnitude(12), HG12{H: 15, G12: tt.g12}.ReducedMagnitude(12))
+	}
+}
+
+func TestDiameter(t *testing.T) {
+	// Ceres, with its measured albedo, comes out close to its real 940 km
+	assert.InDelta(t, 950, Diameter(3.34, 0.09), 5)
+	// H = 17.75 is the usual 1 km cut off for near earth objects
+	assert.InDelta(t, 1.0, Diameter(17.75, DefaultAlbedo), 0.05)
+	// five magnitudes is a factor of ten in size
+	assert.InDelta(t, 10, Diameter(10, 0.2)/Diameter(15, 0.2), 1e-12)
+}
@@ -0,0 +1,106 @@
+package gompcreader
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+/*
+lineSource does the work shared by all the readers in this package. It opens
+the input, detects compression and splits it into lines while keeping track
+of where each line started for error reporting.
+*/
+type lineSource struct {
+	c io.Closer
+	d io.Closer
+	s *bufio.Scanner
+
+	line       int64
+	offset     int64
+	lineOffset int64
+}
+
+/*
+Opens a file and wraps it in a lineSource. The file is closed again if
+anything goes wrong.
+*/
+func openLineSource(filePath string) (*lineSource, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := newLineSource(f, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return src, nil
+}
+
+/*
+Builds a lineSource reading from in. closer, if not nil, is closed along with
+the source.
+*/
+func newLineSource(in io.Reader, closer io.Closer) (*lineSource, error) {
+	plain, decompressor, err := detectCompression(in)
+	if err != nil {
+		return nil, err
+	}
+
+	src := &lineSource{
+		c: closer,
+		d: decompressor,
+		s: bufio.NewScanner(plain),
+	}
+	src.s.Split(src.scanLines)
+	return src, nil
+}
+
+/*
+Wraps bufio.ScanLines so we can keep track of where in the stream each line
+started.
+*/
+func (src *lineSource) scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	if token != nil {
+		src.lineOffset = src.offset
+		src.line = src.line + 1
+	}
+	src.offset = src.offset + int64(advance)
+	return advance, token, err
+}
+
+/*
+Returns the next line, or io.EOF at the end of the input.
+*/
+func (src *lineSource) next() (string, error) {
+	if src.s.Scan() {
+		return src.s.Text(), nil
+	}
+	if err := src.s.Err(); err != nil {
+		return "", err
+	}
+	return "", io.EOF
+}
+
+/*
+Fills in the position of the current line on a ParseError
+*/
+func (src *lineSource) locate(err error) error {
+	if pe, ok := err.(*ParseError); ok {
+		pe.Line = src.line
+		pe.Offset = src.lineOffset
+	}
+	return err
+}
+
+func (src *lineSource) close() {
+	if src.d != nil {
+		src.d.Close()
+	}
+	if src.c != nil {
+		src.c.Close()
+	}
+}
@@ -0,0 +1,99 @@
+package gompcreader
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+/*
+SourceKind says which of the MPC orbit files a reader is reading. They all use
+the MPCORB record layout but differ in what they contain and the text around
+the records.
+*/
+type SourceKind int
+
+const (
+	// SourceUnknown is used when the kind couldn't be worked out, for example
+	// when reading from an io.Reader without a header.
+	SourceUnknown SourceKind = iota
+	// SourceMPCORB is the full MPCORB.DAT file.
+	SourceMPCORB
+	// SourceDaily is DAILY.DAT, the orbits published in the last daily update.
+	SourceDaily
+	// SourceNEA is NEA.txt, the near earth asteroids.
+	SourceNEA
+	// SourcePHA is PHA.txt, the potentially hazardous asteroids.
+	SourcePHA
+	// SourceDistant is Distant.txt, the centaurs and trans-neptunian objects.
+	SourceDistant
+	// SourceUnusual is Unusual.txt, the other unusual objects.
+	SourceUnusual
+)
+
+var sourceKindNames = []struct {
+	kind SourceKind
+	name string
+}{
+	{SourceMPCORB, "mpcorb"},
+	{SourceDaily, "daily"},
+	{SourceNEA, "nea"},
+	{SourcePHA, "pha"},
+	{SourceDistant, "distant"},
+	{SourceUnusual, "unusual"},
+}
+
+func (k SourceKind) String() string {
+	switch k {
+	case SourceMPCORB:
+		return "MPCORB"
+	case SourceDaily:
+		return "DAILY"
+	case SourceNEA:
+		return "NEA"
+	case SourcePHA:
+		return "PHA"
+	case SourceDistant:
+		return "Distant"
+	case SourceUnusual:
+		return "Unusual"
+	}
+	return "Unknown"
+}
+
+/*
+SourceKindFromName works out the kind of file from its name. The directory,
+case and everything from the first '.', such as the extension or a compression
+suffix, are ignored and the rest must be one of the MPC's file names. So
+"NEA.txt.gz" and "/data/nea.TXT" are both SourceNEA, but "nearby_objects.txt"
+is SourceUnknown.
+*/
+func SourceKindFromName(name string) SourceKind {
+	stem := filepath.Base(name)
+	if i := strings.IndexByte(stem, '.'); i >= 0 {
+		stem = stem[:i]
+	}
+	for _, k := range sourceKindNames {
+		if strings.EqualFold(stem, k.name) {
+			return k.kind
+		}
+	}
+	return SourceUnknown
+}
+
+// mpcorbBanner is the first line of the MPCORB.DAT header
+const mpcorbBanner = "MINOR PLANET CENTER ORBIT DATABASE (MPCORB)"
+
+/*
+Some copies of the files have extra whitespace, such as a stray carriage
+return, after the end of each record. A line that is longer than a record but
+is exactly a record once that is stripped is accepted. Shorter lines are never
+records, the last column is the date of the last observation so nothing can be
+missing from the end.
+*/
+func trimmedRecord(line string) (string, bool) {
+	if len(line) <= mpcorbRecordLength {
+		return "", false
+	}
+	trimmed := strings.TrimRight(line, " \t\r")
+	return trimmed, len(trimmed) == mpcorbRecordLength
+}
@@ -0,0 +1,116 @@
+package gompcreader
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var subsetTests = []struct {
+	path      string
+	kind      SourceKind
+	ids       []string
+	nonRecord int64
+}{
+	{samplePath, SourceMPCORB, expectedSampleIDs, 10},
+	{"testdata/subsets/DAILY.DAT", SourceDaily, []string{"2024 PT5", "433"}, 9},
+	{"testdata/subsets/NEA.txt", SourceNEA, []string{"433", "99942", "2024 PT5"}, 0},
+	// ends with a section of notes, some of them as long as a trimmed record
+	{"testdata/subsets/NEA.notes.txt", SourceNEA, []string{"433", "99942", "2024 PT5"}, 7},
+	{"testdata/subsets/PHA.txt", SourcePHA, []string{"99942"}, 0},
+	{"testdata/subsets/Distant.txt", SourceDistant, []string{"15760"}, 0},
+	{"testdata/subsets/Unusual.txt", SourceUnusual, []string{"5335"}, 0},
+}
+
+func TestSubsetFiles(t *testing.T) {
+	for _, tt := range subsetTests {
+		reader, err := NewMpcReader(tt.path)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.ids, readAllIDs(t, reader), tt.path)
+		// the MPCORB sample isn't named MPCORB.DAT, it is found from the header
+		assert.Equal(t, tt.kind, reader.SourceKind(), tt.path)
+		assert.Equal(t, ReaderStats{Read: int64(len(tt.ids)), NonRecordLines: tt.nonRecord}, reader.Stats(), tt.path)
+		reader.Close()
+	}
+}
+
+func TestSubsetFlags(t *testing.T) {
+	reader, err := NewMpcReader("testdata/subsets/PHA.txt")
+	assert.Nil(t, err)
+	defer reader.Close()
+	apophis, err := reader.ReadEntry()
+	assert.Nil(t, err)
+	assert.True(t, apophis.Flags().IsPHA())
+	assert.Equal(t, OrbitTypeAten, apophis.Flags().OrbitType())
+}
+
+func TestSourceKindFromHeader(t *testing.T) {
+	reader, err := NewMpcReaderFromReader(strings.NewReader(string(sampleBytes(t))))
+	assert.Nil(t, err)
+	defer reader.Close()
+	assert.Equal(t, SourceUnknown, reader.SourceKind())
+	readAllIDs(t, reader)
+	assert.Equal(t, SourceMPCORB, reader.SourceKind())
+
+	reader.SetSourceKind(SourceNEA)
+	assert.Equal(t, SourceNEA, reader.SourceKind())
+	assert.Equal(t, "NEA", reader.SourceKind().String())
+}
+
+var trimmedRecordTests = []struct {
+	name  string
+	line  string
+	ids   []string
+	stats ReaderStats
+}{
+	{"trailing spaces", ceresLine + "   ", []string{"1"}, ReaderStats{Read: 1}},
+	{"trailing carriage return", ceresLine + " \r", []string{"1"}, ReaderStats{Read: 1}},
+	{"too long", ceresLine + "0", nil, ReaderStats{NonRecordLines: 1}},
+	{"short", ceresLine[:180], nil, ReaderStats{NonRecordLines: 1}},
+	{"short with trailing spaces", ceresLine[:180] + strings.Repeat(" ", 30), nil, ReaderStats{NonRecordLines: 1}},
+}
+
+func TestTrimmedRecords(t *testing.T) {
+	for _, tt := range trimmedRecordTests {
+		reader, err := NewMpcReaderFromReader(strings.NewReader(tt.line + "\n"))
+		assert.Nil(t, err)
+		var ids []string
+		for {
+			p, err := reader.ReadEntry()
+			if err != nil {
+				assert.Equal(t, io.EOF, err, tt.name)
+				break
+			}
+			ids = append(ids, p.ID)
+		}
+		assert.Equal(t, tt.ids, ids, tt.name)
+		assert.Equal(t, tt.stats, reader.Stats(), tt.name)
+		reader.Close()
+	}
+}
+
+var sourceKindNameTests = []struct {
+	in  string
+	out SourceKind
+}{
+	{"MPCORB.DAT", SourceMPCORB},
+	{"/data/MPCORB.DAT.gz", SourceMPCORB},
+	{"DAILY.DAT", SourceDaily},
+	{"NEA.txt", SourceNEA},
+	{"nea.TXT.bz2", SourceNEA},
+	{"nearby_objects.txt", SourceUnknown},
+	{"phase_curves.dat", SourceUnknown},
+	{"MPCORB_sample.DAT", SourceUnknown},
+	{"PHA.txt", SourcePHA},
+	{"Distant.txt", SourceDistant},
+	{"Unusual.txt", SourceUnusual},
+	{"CometEls.txt", SourceUnknown},
+}
+
+func TestSourceKindFromName(t *testing.T) {
+	for _, tt := range sourceKindNameTests {
+		assert.Equal(t, tt.out, SourceKindFromName(tt.in), tt.in)
+	}
+}
@@ -0,0 +1,94 @@
+//go:build ignore
+// +build ignore
+
+/*
+Generates ceres_vectors.txt, the reference states used by
+TestMinorPlanetPositionAt. It is deliberately independent of the package: it
+solves Kepler's equation by bisection and builds the state from the true
+anomaly and the radial and transverse velocities, rather than the eccentric
+anomaly form used by PositionAt.
+
+Run it from the repository root with
+
+	go run testdata/gen_ceres_vectors.go > testdata/ceres_vectors.txt
+*/
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// The elements in ceresLine in mpcreader_test.go
+const (
+	meanAnomaly = 10.55761
+	peri        = 72.29213
+	node        = 80.32762
+	incl        = 10.59398
+	e           = 0.0757973
+	n           = 0.21415869
+	a           = 2.7668073
+)
+
+const rad = math.Pi / 180
+
+func kepler(m float64) float64 {
+	m = math.Mod(m, 2*math.Pi)
+	if m < 0 {
+		m += 2 * math.Pi
+	}
+	lo, hi := 0.0, 2*math.Pi
+	for i := 0; i < 200; i++ {
+		mid := (lo + hi) / 2
+		if mid-e*math.Sin(mid)-m > 0 {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+func main() {
+	epoch := time.Date(2013, 11, 4, 0, 0, 0, 0, time.UTC)
+	days := func(d float64) time.Duration { return time.Duration(d * 24 * float64(time.Hour)) }
+	times := []time.Time{
+		epoch,
+		epoch.Add(days(100.5)),
+		epoch.Add(days(1000)),
+		epoch.Add(-days(2000.25)),
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1801, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	mu := math.Pow(n*rad, 2) * a * a * a
+
+	fmt.Println("# Heliocentric ecliptic J2000 state of (1) Ceres from the two-body propagation")
+	fmt.Println("# of the elements in ceresLine (mpcreader_test.go). Generated by")
+	fmt.Println("# testdata/gen_ceres_vectors.go, which uses its own bisection Kepler solver.")
+	fmt.Println("# Columns: time (TT) x y z (AU) vx vy vz (AU/day)")
+	for _, t := range times {
+		dt := t.Sub(epoch).Seconds() / 86400
+		ea := kepler((meanAnomaly + n*dt) * rad)
+		nu := 2 * math.Atan2(math.Sqrt(1+e)*math.Sin(ea/2), math.Sqrt(1-e)*math.Cos(ea/2))
+		p := a * (1 - e*e)
+		r := p / (1 + e*math.Cos(nu))
+		vr := math.Sqrt(mu/p) * e * math.Sin(nu)
+		vt := math.Sqrt(mu/p) * (1 + e*math.Cos(nu))
+
+		u := peri*rad + nu
+		rot := func(x, y float64) [3]float64 {
+			// x is radial and y transverse in the orbit plane
+			cu, su := math.Cos(u), math.Sin(u)
+			px, py := x*cu-y*su, x*su+y*cu
+			return [3]float64{
+				px*math.Cos(node*rad) - py*math.Sin(node*rad)*math.Cos(incl*rad),
+				px*math.Sin(node*rad) + py*math.Cos(node*rad)*math.Cos(incl*rad),
+				py * math.Sin(incl*rad),
+			}
+		}
+		pos, vel := rot(r, 0), rot(vr, vt)
+		fmt.Printf("%s %.12f %.12f %.12f %.14f %.14f %.14f\n", t.Format("2006-01-02T15:04:05Z"),
+			pos[0], pos[1], pos[2], vel[0], vel[1], vel[2])
+	}
+}
@@ -0,0 +1,103 @@
+package gompcreader
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+/*
+The dates UTC gained a leap second from, and TAI-UTC in seconds from then on.
+This needs a new entry whenever the IERS announces another leap second.
+*/
+var leapSeconds = []struct {
+	from   time.Time
+	offset float64
+}{
+	{time.Date(1972, 1, 1, 0, 0, 0, 0, time.UTC), 10},
+	{time.Date(1972, 7, 1, 0, 0, 0, 0, time.UTC), 11},
+	{time.Date(1973, 1, 1, 0, 0, 0, 0, time.UTC), 12},
+	{time.Date(1974, 1, 1, 0, 0, 0, 0, time.UTC), 13},
+	{time.Date(1975, 1, 1, 0, 0, 0, 0, time.UTC), 14},
+	{time.Date(1976, 1, 1, 0, 0, 0, 0, time.UTC), 15},
+	{time.Date(1977, 1, 1, 0, 0, 0, 0, time.UTC), 16},
+	{time.Date(1978, 1, 1, 0, 0, 0, 0, time.UTC), 17},
+	{time.Date(1979, 1, 1, 0, 0, 0, 0, time.UTC), 18},
+	{time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), 19},
+	{time.Date(1981, 7, 1, 0, 0, 0, 0, time.UTC), 20},
+	{time.Date(1982, 7, 1, 0, 0, 0, 0, time.UTC), 21},
+	{time.Date(1983, 7, 1, 0, 0, 0, 0, time.UTC), 22},
+	{time.Date(1985, 7, 1, 0, 0, 0, 0, time.UTC), 23},
+	{time.Date(1988, 1, 1, 0, 0, 0, 0, time.UTC), 24},
+	{time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), 25},
+	{time.Date(1991, 1, 1, 0, 0, 0, 0, time.UTC), 26},
+	{time.Date(1992, 7, 1, 0, 0, 0, 0, time.UTC), 27},
+	{time.Date(1993, 7, 1, 0, 0, 0, 0, time.UTC), 28},
+	{time.Date(1994, 7, 1, 0, 0, 0, 0, time.UTC), 29},
+	{time.Date(1996, 1, 1, 0, 0, 0, 0, time.UTC), 30},
+	{time.Date(1997, 7, 1, 0, 0, 0, 0, time.UTC), 31},
+	{time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC), 32},
+	{time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC), 33},
+	{time.Date(2009, 1, 1, 0, 0, 0, 0, time.UTC), 34},
+	{time.Date(2012, 7, 1, 0, 0, 0, 0, time.UTC), 35},
+	{time.Date(2015, 7, 1, 0, 0, 0, 0, time.UTC), 36},
+	{time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), 37},
+}
+
+// ttMinusTAI is the fixed offset between TT and TAI in seconds.
+const ttMinusTAI = 32.184
+
+/*
+Returns TT-UTC at t. From 1972 this is exact, from the leap second table.
+Before that UTC didn't have whole second steps, so delta T, TT-UT1, is used
+instead. It comes from the Espenak and Meeus polynomials, and before 1800 from
+their long term parabola.
+*/
+func terrestrialTimeOffset(t time.Time) time.Duration {
+	i := sort.Search(len(leapSeconds), func(i int) bool { return leapSeconds[i].from.After(t) })
+	if i > 0 {
+		return seconds(ttMinusTAI + leapSeconds[i-1].offset)
+	}
+
+	y := float64(t.Year()) + (float64(t.YearDay())-0.5)/365.25
+	var dt float64
+	switch {
+	case y < 1800:
+		u := (y - 1820) / 100
+		dt = -20 + 32*u*u
+	case y < 1860:
+		u := y - 1800
+		dt = 13.72 - 0.332447*u + 0.0068612*math.Pow(u, 2) + 0.0041116*math.Pow(u, 3) -
+			0.00037436*math.Pow(u, 4) + 0.0000121272*math.Pow(u, 5) -
+			0.0000001699*math.Pow(u, 6) + 0.000000000875*math.Pow(u, 7)
+	case y < 1900:
+		u := y - 1860
+		dt = 7.62 + 0.5737*u - 0.251754*math.Pow(u, 2) + 0.01680668*math.Pow(u, 3) -
+			0.0004473624*math.Pow(u, 4) + math.Pow(u, 5)/233174
+	case y < 1920:
+		u := y - 1900
+		dt = -2.79 + 1.494119*u - 0.0598939*math.Pow(u, 2) + 0.0061966*math.Pow(u, 3) -
+			0.000197*math.Pow(u, 4)
+	case y < 1941:
+		u := y - 1920
+		dt = 21.20 + 0.84493*u - 0.076100*math.Pow(u, 2) + 0.0020936*math.Pow(u, 3)
+	case y < 1961:
+		u := y - 1950
+		dt = 29.07 + 0.407*u - math.Pow(u, 2)/233 + math.Pow(u, 3)/2547
+	default:
+		u := y - 1975
+		dt = 45.45 + 1.067*u - math.Pow(u, 2)/260 - math.Pow(u, 3)/718
+	}
+	return seconds(dt)
+}
+
+/*
+Converts a UTC time to TT, the time scale the orbital elements use.
+*/
+func terrestrialTime(t time.Time) time.Time {
+	return t.Add(terrestrialTimeOffset(t))
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s * float64(time.Second)))
+}
@@ -0,0 +1,35 @@
+package gompcreader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var terrestrialTimeOffsetTests = []struct {
+	t      time.Time
+	offset float64
+	delta  float64
+}{
+	{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 69.184, 0},
+	{time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), 69.184, 0},
+	{time.Date(2016, 12, 31, 23, 59, 59, 0, time.UTC), 68.184, 0},
+	{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC), 64.184, 0},
+	{time.Date(1972, 1, 1, 0, 0, 0, 0, time.UTC), 42.184, 0},
+	// before leap seconds, against the Espenak and Meeus table of delta T
+	{time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), 40.2, 0.5},
+	{time.Date(1950, 1, 1, 0, 0, 0, 0, time.UTC), 29.1, 0.5},
+	{time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), -2.7, 0.5},
+	{time.Date(1850, 1, 1, 0, 0, 0, 0, time.UTC), 7.1, 0.5},
+	{time.Date(1800, 1, 1, 0, 0, 0, 0, time.UTC), 13.7, 0.5},
+}
+
+func TestTerrestrialTimeOffset(t *testing.T) {
+	for _, tt := range terrestrialTimeOffsetTests {
+		assert.InDelta(t, tt.offset, terrestrialTimeOffset(tt.t).Seconds(), tt.delta+1e-9, "TT-UTC at %s", tt.t)
+	}
+
+	when := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, when.Add(69184*time.Millisecond), terrestrialTime(when))
+}
@@ -0,0 +1,109 @@
+package gompcreader
+
+import (
+	"math"
+)
+
+/*
+Uncertainty is the decoded form of the UncertaintyParameter column.
+
+The column holds the MPC's orbit quality U from 0 (best) to 9 (worst), an "E"
+when the eccentricity was assumed, a "D" when the object has a double
+designation problem, or is blank when no value was given.
+*/
+type Uncertainty byte
+
+/*
+Uncertainty decodes UncertaintyParameter
+*/
+func (p *MinorPlanet) Uncertainty() Uncertainty {
+	return ParseUncertainty(p.UncertaintyParameter)
+}
+
+/*
+ParseUncertainty decodes the single character uncertainty column. Anything
+blank or empty gives the zero Uncertainty.
+*/
+func ParseUncertainty(value string) Uncertainty {
+	s := readString(value)
+	if s == "" {
+		return 0
+	}
+	return Uncertainty(s[0])
+}
+
+/*
+IsKnown reports if the value is a numeric U between 0 and 9
+*/
+func (u Uncertainty) IsKnown() bool {
+	return u >= '0' && u <= '9'
+}
+
+/*
+U returns the numeric orbit quality. This is -1 when IsKnown is false.
+*/
+func (u Uncertainty) U() int {
+	if !u.IsKnown() {
+		return -1
+	}
+	return int(u - '0')
+}
+
+/*
+IsBlank is true when the column was empty
+*/
+func (u Uncertainty) IsBlank() bool {
+	return u == 0
+}
+
+/*
+IsEccentricityAssumed is true for "E", the orbit was computed with an assumed
+eccentricity
+*/
+func (u Uncertainty) IsEccentricityAssumed() bool {
+	return u == 'E'
+}
+
+/*
+IsDoubleDesignation is true for "D", the object has been assigned more than
+one designation and the identification is in doubt
+*/
+func (u Uncertainty) IsDoubleDesignation() bool {
+	return u == 'D'
+}
+
+func (u Uncertainty) String() string {
+	if u == 0 {
+		return ""
+	}
+	return string(rune(u))
+}
+
+/*
+The MPC defines U from the in-orbit longitude runoff over ten years, in arc
+seconds. Each step of U covers a factor of 648000^(1/9) in runoff so that U 9
+starts at a bit under 41 degrees per decade.
+*/
+var runoffStep = math.Log(648000) / 9
+
+/*
+RunoffPerDecade returns the range of the expected along-track uncertainty in
+the ephemeris after ten years, in arc seconds, for this value of U. U 0 has a
+lower bound of zero and U 9 has no upper bound so returns +Inf.
+
+ok is false if the value is not a known U.
+*/
+func (u Uncertainty) RunoffPerDecade() (lower float64, upper float64, ok bool) {
+	if !u.IsKnown() {
+		return 0, 0, false
+	}
+	n := float64(u.U())
+	if u.U() > 0 {
+		lower = math.Exp((n - 1) * runoffStep)
+	}
+	upper = math.Exp(n * runoffStep)
+	if u.U() == 9 {
+		upper = math.Inf(1)
+	}
+	return lower, upper, true
+}
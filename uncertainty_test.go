@@ -0,0 +1,80 @@
+package gompcreader
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var uncertaintyTests = []struct {
+	in         string
+	known      bool
+	u          int
+	blank      bool
+	assumed    bool
+	doubleDesg bool
+}{
+	{"0", true, 0, false, false, false},
+	{"9", true, 9, false, false, false},
+	{" 5", true, 5, false, false, false},
+	{"E", false, -1, false, true, false},
+	{"D", false, -1, false, false, true},
+	{" ", false, -1, true, false, false},
+	{"", false, -1, true, false, false},
+}
+
+func TestUncertainty(t *testing.T) {
+	for _, tt := range uncertaintyTests {
+		u := ParseUncertainty(tt.in)
+		assert.Equal(t, tt.known, u.IsKnown(), "IsKnown(%q)", tt.in)
+		assert.Equal(t, tt.u, u.U(), "U(%q)", tt.in)
+		assert.Equal(t, tt.blank, u.IsBlank(), "IsBlank(%q)", tt.in)
+		assert.Equal(t, tt.assumed, u.IsEccentricityAssumed(), "IsEccentricityAssumed(%q)", tt.in)
+		assert.Equal(t, tt.doubleDesg, u.IsDoubleDesignation(), "IsDoubleDesignation(%q)", tt.in)
+		assert.Equal(t, readString(tt.in), u.String())
+	}
+}
+
+var runoffTests = []struct {
+	u     string
+	lower float64
+	upper float64
+}{
+	{"0", 0, 1.0},
+	{"1", 1.0, 4.4},
+	{"2", 4.4, 19.6},
+	{"5", 382, 1692},
+	{"8", 33121, 146502},
+	{"9", 146502, math.Inf(1)},
+}
+
+func TestRunoffPerDecade(t *testing.T) {
+	for _, tt := range runoffTests {
+		lower, upper, ok := ParseUncertainty(tt.u).RunoffPerDecade()
+		assert.True(t, ok)
+		if tt.lower == 0 {
+			assert.Equal(t, 0.0, lower)
+		} else {
+			assert.InEpsilon(t, tt.lower, lower, 0.01, "lower bound of U %s", tt.u)
+		}
+		if math.IsInf(tt.upper, 1) {
+			assert.True(t, math.IsInf(upper, 1))
+		} else {
+			assert.InEpsilon(t, tt.upper, upper, 0.01, "upper bound of U %s", tt.u)
+		}
+	}
+
+	_, _, ok := ParseUncertainty("E").RunoffPerDecade()
+	assert.False(t, ok)
+}
+
+func TestMinorPlanetUncertainty(t *testing.T) {
+	result, err := ParseRecord(ceresLine)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Uncertainty().U())
+
+	result, err = ParseRecord(t3s5154Line)
+	assert.Nil(t, err)
+	assert.True(t, result.Uncertainty().IsBlank())
+}
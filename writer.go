@@ -0,0 +1,211 @@
+package gompcreader
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+/*
+MpcWriter writes MinorPlanet records in the 202 column MPCORB format so they
+can be read by this package or any other MPC aware tool.
+
+Should be constructed using NewMpcWriter(io.Writer). Output is buffered so
+Flush() must be called once all the records have been written.
+*/
+type MpcWriter struct {
+	w *bufio.Writer
+}
+
+/*
+NewMpcWriter creates a writer that writes records to out. The caller is
+responsible for closing out once the writer has been flushed.
+*/
+func NewMpcWriter(out io.Writer) *MpcWriter {
+	return &MpcWriter{w: bufio.NewWriter(out)}
+}
+
+/*
+WriteEntry formats the minor planet and writes it as a single line.
+
+If the minor planet can't be represented in the fixed width format nothing is
+written and an error is returned.
+*/
+func (writer *MpcWriter) WriteEntry(p *MinorPlanet) error {
+	line, err := FormatRecord(p)
+	if err != nil {
+		return err
+	}
+	if _, err = writer.w.WriteString(line); err != nil {
+		return err
+	}
+	return writer.w.WriteByte('\n')
+}
+
+/*
+Flush writes any buffered records to the underlying io.Writer.
+*/
+func (writer *MpcWriter) Flush() error {
+	return writer.w.Flush()
+}
+
+/*
+FormatRecord converts a minor planet into a 202 character MPCORB line. This is
+the inverse of ParseRecord.
+
+The Designation is packed if its Kind is set, otherwise the ID is. The Epoch
+is packed too, and optional fields listed in Missing() are left blank.
+Columns 128-136 hold either the years of the first and last observation
+or the arc length in days depending on NumberOfOppositions, in the same way as
+the reader.
+
+See readableDesignation for how columns 167-194 are filled in.
+
+An error is returned if the designation can't be packed or a value doesn't fit in its
+column.
+*/
+func FormatRecord(p *MinorPlanet) (string, error) {
+	line := []byte(strings.Repeat(" ", mpcorbRecordLength))
+
+	var id string
+	var err error
+	if p.Designation.Kind != 0 {
+		id, err = p.Designation.Packed()
+	} else {
+		id, err = packIdentifier(p.ID)
+	}
+	if err != nil {
+		return "", colID.formatError(err)
+	}
+	epoch, err := packTime(p.Epoch)
+	if err != nil {
+		return "", colEpoch.formatError(err)
+	}
+
+	var fields = []formatField{
+		{colID, id},
+		{colAbsoluteMagnitude, optional(p.HasAbsoluteMagnitude(), "%5.2f", p.AbsoluteMagnitude)},
+		{colSlope, optional(p.HasSlope(), "%5.2f", p.Slope)},
+		{colEpoch, epoch},
+		{colMeanAnomalyEpoch, fmt.Sprintf("%9.5f", p.MeanAnomalyEpoch)},
+		{colArgumentOfPerihelion, fmt.Sprintf("%9.5f", p.ArgumentOfPerihelion)},
+		{colLongitudeOfTheAscendingNode, fmt.Sprintf("%9.5f", p.LongitudeOfTheAscendingNode)},
+		{colInclinationToTheEcliptic, fmt.Sprintf("%9.5f", p.InclinationToTheEcliptic)},
+		{colOrbitalEccentricity, fmt.Sprintf("%9.7f", p.OrbitalEccentricity)},
+		{colMeanDailyMotion, fmt.Sprintf("%11.8f", p.MeanDailyMotion)},
+		{colSemimajorAxis, fmt.Sprintf("%11.7f", p.SemimajorAxis)},
+		{colUncertaintyParameter, p.UncertaintyParameter},
+		{colReference, p.Reference},
+		{colNumberOfObservations, optional(p.HasNumberOfObservations(), "%5d", p.NumberOfObservations)},
+		{colNumberOfOppositions, optional(p.HasNumberOfOppositions(), "%3d", p.NumberOfOppositions)},
+		{colRMSResidual, optional(p.HasRMSResidual(), "%4.2f", p.RMSResidual)},
+		{colCoarseIndicatorOfPerturbers, p.CoarseIndicatorOfPerturbers},
+		{colPreciseIndicatorOfPerturbers, p.PreciseIndicatorOfPerturbers},
+		{colComputerName, p.ComputerName},
+		{colHexDigitFlags, fmt.Sprintf("%04x", p.HexDigitFlags)},
+		{colReadableDesignation, formatReadableDesignation(readableDesignation(p))},
+		{colDateOfLastObservation, p.DateOfLastObservation.Format("20060102")},
+	}
+
+	// Mirror the reader, see convertToMinorPlanet
+	if p.NumberOfOppositions > 1 {
+		fields = append(fields, formatField{colArcLength,
+			fmt.Sprintf("%4d-%4d", p.YearOfFirstObservation, p.YearOfLastObservation)})
+	} else {
+		fields = append(fields, formatField{colArcLength, fmt.Sprintf("%4d days", p.ArcLength)})
+	}
+
+	for _, f := range fields {
+		if err := f.c.put(line, f.value); err != nil {
+			return "", err
+		}
+	}
+
+	return string(line), nil
+}
+
+/*
+Formats an optional field, leaving it blank if the field has no value. See
+MinorPlanet.Missing.
+*/
+func optional(present bool, format string, value interface{}) string {
+	if !present {
+		return ""
+	}
+	return fmt.Sprintf(format, value)
+}
+
+type formatField struct {
+	c     column
+	value string
+}
+
+/*
+Copy value into this column of line, left justified. Numbers should already
+be padded to the right width by their format string.
+*/
+func (c column) put(line []byte, value string) error {
+	if len(value) > c.end-c.start {
+		return c.formatError(ErrFieldWidth)
+	}
+	copy(line[c.start:c.end], value)
+	return nil
+}
+
+/*
+Wrap an error from writing this column so the caller knows which field could
+not be formatted.
+*/
+func (c column) formatError(err error) error {
+	return fmt.Errorf("%s (columns %d-%d): %w", c.field, c.start+1, c.end, err)
+}
+
+/*
+Works out what to put in the readable designation column. Number, Name and
+ProvisionalDesignation are used if any of them are set, otherwise
+ReadableDesignation is written as is.
+*/
+func readableDesignation(p *MinorPlanet) string {
+	if p.Number != 0 || p.Name != "" || p.ProvisionalDesignation != "" {
+		return joinReadableDesignation(p.Number, p.Name, p.ProvisionalDesignation)
+	}
+	return p.ReadableDesignation
+}
+
+/*
+The readable designation is laid out so that the name or provisional
+designation always starts at column 176. For numbered objects the number in
+brackets is right aligned in front of it.
+*/
+func formatReadableDesignation(designation string) string {
+	const nameStart = 9
+	if strings.HasPrefix(designation, "(") {
+		end := strings.Index(designation, ")")
+		if end > 0 && end < nameStart-1 {
+			number := designation[:end+1]
+			rest := strings.TrimLeft(designation[end+1:], " ")
+			return fmt.Sprintf("%*s %s", nameStart-1, number, rest)
+		}
+		return designation
+	}
+	if len(designation)+nameStart <= colReadableDesignation.end-colReadableDesignation.start {
+		return strings.Repeat(" ", nameStart) + designation
+	}
+	return designation
+}
+
+/*
+Packs a time into the five character year, month, day form read by
+readPackedTime.
+*/
+func packTime(t time.Time) (string, error) {
+	century, ok := packDigit(int64(t.Year() / 100))
+	if !ok {
+		return "", ErrFieldWidth
+	}
+	month, _ := packDigit(int64(t.Month()))
+	day, _ := packDigit(int64(t.Day()))
+	return fmt.Sprintf("%c%02d%c%c", century, t.Year()%100, month, day), nil
+}
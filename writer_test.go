@@ -0,0 +1,125 @@
+package gompcreader
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatRecordRoundTrip(t *testing.T) {
+	for _, line := range []string{ceresLine, t3s5154Line} {
+		expected, err := ParseRecord(line)
+		assert.Nil(t, err)
+
+		formatted, err := FormatRecord(expected)
+		assert.Nil(t, err)
+		assert.Len(t, formatted, 202)
+
+		actual, err := ParseRecord(formatted)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual, "round trip of %s", expected.ID)
+	}
+}
+
+func TestFormatRecordCeres(t *testing.T) {
+	p, err := ParseRecord(ceresLine)
+	assert.Nil(t, err)
+
+	formatted, err := FormatRecord(p)
+	assert.Nil(t, err)
+	assert.Equal(t, ceresLine, formatted)
+}
+
+func TestFormatRecordDesignation(t *testing.T) {
+	p, _ := ParseRecord(ceresLine)
+
+	// only the typed designation is set
+	p.ID = ""
+	p.Designation = Designation{Kind: DesignationProvisional, Year: 2014, HalfMonth: 'A', Letter: 'B', Cycle: 123}
+	formatted, err := FormatRecord(p)
+	assert.Nil(t, err)
+	assert.Equal(t, "K14AC3B", formatted[0:7])
+
+	result, err := ParseRecord(formatted)
+	assert.Nil(t, err)
+	assert.Equal(t, "2014 AB123", result.ID)
+	assert.Equal(t, p.Designation, result.Designation)
+
+	// and it wins over a stale ID
+	p.ID = "1"
+	formatted, err = FormatRecord(p)
+	assert.Nil(t, err)
+	assert.Equal(t, "K14AC3B", formatted[0:7])
+
+	p.Designation = Designation{Kind: DesignationNumbered, Number: -1}
+	_, err = FormatRecord(p)
+	assert.True(t, errors.Is(err, ErrInvalidDesignation))
+}
+
+func TestFormatRecordErrors(t *testing.T) {
+	p, _ := ParseRecord(ceresLine)
+
+	p.ID = "not a designation"
+	p.Designation = Designation{}
+	_, err := FormatRecord(p)
+	assert.True(t, errors.Is(err, ErrInvalidDesignation))
+
+	p.ID = "1"
+	p.SemimajorAxis = 123456.789
+	_, err = FormatRecord(p)
+	assert.True(t, errors.Is(err, ErrFieldWidth))
+	assert.Contains(t, err.Error(), "SemimajorAxis")
+}
+
+func TestMpcWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewMpcWriter(&out)
+	for _, line := range []string{ceresLine, t3s5154Line} {
+		p, _ := ParseRecord(line)
+		assert.Nil(t, writer.WriteEntry(p))
+	}
+	assert.Nil(t, writer.Flush())
+
+	reader, err := NewMpcReaderFromReader(&out)
+	assert.Nil(t, err)
+	assert.Equal(t, expectedSampleIDs, readAllIDs(t, reader))
+}
+
+func TestPackTime(t *testing.T) {
+	for _, tt := range packedDateTests {
+		r, e := packTime(tt.out)
+		assert.Nil(t, e)
+		assert.Equal(t, strings.TrimSpace(tt.in), r)
+	}
+	_, e := packTime(time.Date(900, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, e)
+}
+
+func TestFormatRecordBlankFields(t *testing.T) {
+	line := blankColumns(ceresLine, colAbsoluteMagnitude, colSlope,
+		colNumberOfObservations, colRMSResidual)
+	p, err := ParseRecord(line)
+	assert.Nil(t, err)
+
+	formatted, err := FormatRecord(p)
+	assert.Nil(t, err)
+	assert.Equal(t, line, formatted)
+
+	// a real zero is still written
+	p.SetMissing(0)
+	formatted, err = FormatRecord(p)
+	assert.Nil(t, err)
+	assert.Equal(t, " 0.00", formatted[colAbsoluteMagnitude.start:colAbsoluteMagnitude.end])
+	assert.Equal(t, "0.00", formatted[colRMSResidual.start:colRMSResidual.end])
+
+	// and a value can be blanked from code
+	ceres, _ := ParseRecord(ceresLine)
+	ceres.SetMissing(ceres.Missing() | FieldRMSResidual)
+	formatted, err = FormatRecord(ceres)
+	assert.Nil(t, err)
+	assert.Equal(t, "    ", formatted[colRMSResidual.start:colRMSResidual.end])
+}